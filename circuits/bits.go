@@ -0,0 +1,54 @@
+package circuits
+
+import "github.com/consensys/gnark/frontend"
+
+// Helpers of the byte-oriented hashes (keccak.go, sha256.go, hashes.go): the messages and the digests are slices of bytes, the
+// words are assembled from their bits.
+
+// bytesToBits returns the bits of the bytes, least significant first: the 8 bits of bytes[0], then the 8 bits of
+// bytes[1]... The decomposition range checks the bytes.
+func bytesToBits(api frontend.API, bytes []frontend.Variable) []frontend.Variable {
+	bits := make([]frontend.Variable, 0, 8*len(bytes))
+	for _, b := range bytes {
+		bits = append(bits, api.ToBinary(b, 8)...)
+	}
+	return bits
+}
+
+// bitsToBytes is the inverse of bytesToBits, len(bits) is a multiple of 8
+func bitsToBytes(api frontend.API, bits []frontend.Variable) []frontend.Variable {
+	bytes := make([]frontend.Variable, len(bits)/8)
+	for i := range bytes {
+		bytes[i] = api.FromBinary(bits[i*8 : (i+1)*8]...)
+	}
+	return bytes
+}
+
+// xorBits returns a ^ b for bits a and b, as (a - b)²: 1 constraint like api.Xor, which only reads the first term of
+// its operands in the R1CS builder, whereas a and b may be any linear expression of bits (e.g. 1 - x)
+func xorBits(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	d := api.Sub(a, b)
+	res := api.Mul(d, d)
+	api.Compiler().MarkBoolean(res)
+	return res
+}
+
+// lessEqBytes32 returns 1 if a <= b as big-endian 256-bit integers, 0 otherwise: they are compared by halves of 128
+// bits, a <= b when a.high < b.high or a.high = b.high and a.low <= b.low
+func lessEqBytes32(api frontend.API, a, b [32]frontend.Variable) frontend.Variable {
+	const half = 16
+	pack := func(bytes []frontend.Variable) frontend.Variable {
+		var v frontend.Variable = 0
+		for _, byt := range bytes {
+			v = api.Add(api.Mul(v, 256), byt)
+		}
+		return v
+	}
+	aHigh, aLow := pack(a[:half]), pack(a[half:])
+	bHigh, bLow := pack(b[:half]), pack(b[half:])
+	highLess := LessThanBits(api, aHigh, bHigh, 8*half)
+	highEq := api.IsZero(api.Sub(aHigh, bHigh))
+	lowLessEq := LessEqThanBits(api, aLow, bLow, 8*half)
+	// highLess and highEq are never both set
+	return api.Add(highLess, api.Mul(highEq, lowLessEq))
+}
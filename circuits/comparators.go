@@ -2,8 +2,11 @@ package circuits
 
 import "github.com/consensys/gnark/frontend"
 
-func IsZero(api frontend.API, a frontend.Variable) frontend.Variable {
-	return api.IsZero(a)
+func IsZero(api frontend.API, a frontend.Variable) (res frontend.Variable) {
+	Gadget(api, GadgetComparator, func() {
+		res = api.IsZero(a)
+	})
+	return res
 }
 
 func BoolNeg(api frontend.API, a frontend.Variable) frontend.Variable {
@@ -11,27 +14,44 @@ func BoolNeg(api frontend.API, a frontend.Variable) frontend.Variable {
 	return api.Xor(a, 1)
 }
 
-func IsEqual(api frontend.API, a frontend.Variable, b frontend.Variable) frontend.Variable {
-	return api.IsZero(api.Sub(a, b))
+func IsEqual(api frontend.API, a frontend.Variable, b frontend.Variable) (res frontend.Variable) {
+	Gadget(api, GadgetComparator, func() {
+		res = api.IsZero(api.Sub(a, b))
+	})
+	return res
 }
 
 func ForceEqualIfEnabled(api frontend.API, a, b, enabled frontend.Variable) {
-	c := api.IsZero(api.Sub(a, b))
-	api.AssertIsEqual(api.Mul(api.Sub(1, c), enabled), 0)
+	Gadget(api, GadgetComparator, func() {
+		c := api.IsZero(api.Sub(a, b))
+		api.AssertIsEqual(api.Mul(api.Sub(1, c), enabled), 0)
+	})
 }
 
-func LessThan(api frontend.API, a frontend.Variable, b frontend.Variable) frontend.Variable {
-	return IsEqual(api, api.Cmp(a, b), -1)
+func LessThan(api frontend.API, a frontend.Variable, b frontend.Variable) (res frontend.Variable) {
+	Gadget(api, GadgetComparator, func() {
+		res = IsEqual(api, api.Cmp(a, b), -1)
+	})
+	return res
 }
 
-func LessEqThan(api frontend.API, a frontend.Variable, b frontend.Variable) frontend.Variable {
-	return BoolNeg(api, GreaterThan(api, a, b))
+func LessEqThan(api frontend.API, a frontend.Variable, b frontend.Variable) (res frontend.Variable) {
+	Gadget(api, GadgetComparator, func() {
+		res = BoolNeg(api, GreaterThan(api, a, b))
+	})
+	return res
 }
 
-func GreaterThan(api frontend.API, a frontend.Variable, b frontend.Variable) frontend.Variable {
-	return IsEqual(api, api.Cmp(a, b), 1)
+func GreaterThan(api frontend.API, a frontend.Variable, b frontend.Variable) (res frontend.Variable) {
+	Gadget(api, GadgetComparator, func() {
+		res = IsEqual(api, api.Cmp(a, b), 1)
+	})
+	return res
 }
 
-func GreaterEqThan(api frontend.API, a frontend.Variable, b frontend.Variable) frontend.Variable {
-	return BoolNeg(api, LessThan(api, a, b))
+func GreaterEqThan(api frontend.API, a frontend.Variable, b frontend.Variable) (res frontend.Variable) {
+	Gadget(api, GadgetComparator, func() {
+		res = BoolNeg(api, LessThan(api, a, b))
+	})
+	return res
 }
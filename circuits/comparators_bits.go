@@ -0,0 +1,135 @@
+package circuits
+
+import (
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"math/big"
+)
+
+// The comparators of comparators.go decompose both operands over the whole field (api.Cmp), over 4000 constraints
+// for LessThan. The *Bits comparators work on n-bit operands: they range check the operands and decompose a single
+// n+1 bits difference, 3n+3 constraints. n is at most MaxComparatorBits, so that a + 2ⁿ - b never wraps around.
+
+// MaxComparatorBits is the largest bit size of the operands of the *Bits comparators
+const MaxComparatorBits = 252
+
+func checkComparatorBits(n int) {
+	if n < 1 || n > MaxComparatorBits {
+		panic(fmt.Sprintf("comparator: %d bits operands, must be in [1, %d]", n, MaxComparatorBits))
+	}
+}
+
+// RangeCheck asserts that x < 2ⁿ, with n+1 constraints
+func RangeCheck(api frontend.API, x frontend.Variable, n int) {
+	if n < 1 || n >= fr.Bits {
+		panic(fmt.Sprintf("range check: %d bits, must be in [1, %d]", n, fr.Bits-1))
+	}
+	Gadget(api, GadgetComparator, func() {
+		// the bits are boolean and recompose x
+		api.ToBinary(x, n)
+	})
+}
+
+// AssertInRange asserts that lo <= x <= hi, where lo and hi are n-bit values with lo <= hi (n-bit variables are
+// range checked, constants are checked at compile time)
+func AssertInRange(api frontend.API, x, lo, hi frontend.Variable, n int) {
+	checkComparatorBits(n)
+	Gadget(api, GadgetComparator, func() {
+		bounds := [2]*big.Int{}
+		for i, bound := range []frontend.Variable{lo, hi} {
+			if c, ok := api.Compiler().ConstantValue(bound); ok {
+				if c.Sign() < 0 || c.BitLen() > n {
+					panic(fmt.Sprintf("range: bound %s is not a %d bits value", c, n))
+				}
+				bounds[i] = c
+				continue
+			}
+			RangeCheck(api, bound, n)
+		}
+		if bounds[0] != nil && bounds[1] != nil && bounds[0].Cmp(bounds[1]) > 0 {
+			panic(fmt.Sprintf("range: empty range [%s, %s]", bounds[0], bounds[1]))
+		}
+		// x - lo and hi - x are in [0, 2ⁿ) and add up to hi - lo < 2ⁿ without wrapping around
+		RangeCheck(api, api.Sub(x, lo), n)
+		RangeCheck(api, api.Sub(hi, x), n)
+	})
+}
+
+// LessThanBits returns 1 if a < b, 0 otherwise, for n-bit a and b
+func LessThanBits(api frontend.API, a, b frontend.Variable, n int) (res frontend.Variable) {
+	checkComparatorBits(n)
+	Gadget(api, GadgetComparator, func() {
+		RangeCheck(api, a, n)
+		RangeCheck(api, b, n)
+		res = lessThanBits(api, a, b, n)
+	})
+	return res
+}
+
+// LessEqThanBits returns 1 if a <= b, 0 otherwise, for n-bit a and b
+func LessEqThanBits(api frontend.API, a, b frontend.Variable, n int) frontend.Variable {
+	return api.Sub(1, GreaterThanBits(api, a, b, n))
+}
+
+// GreaterThanBits returns 1 if a > b, 0 otherwise, for n-bit a and b
+func GreaterThanBits(api frontend.API, a, b frontend.Variable, n int) frontend.Variable {
+	return LessThanBits(api, b, a, n)
+}
+
+// GreaterEqThanBits returns 1 if a >= b, 0 otherwise, for n-bit a and b
+func GreaterEqThanBits(api frontend.API, a, b frontend.Variable, n int) frontend.Variable {
+	return api.Sub(1, LessThanBits(api, a, b, n))
+}
+
+// lessThanBits doesn't range check a and b: a + 2ⁿ - b has n+1 bits, the top one is set iff a >= b
+func lessThanBits(api frontend.API, a, b frontend.Variable, n int) frontend.Variable {
+	bits := api.ToBinary(api.Sub(api.Add(a, new(big.Int).Lsh(big.NewInt(1), uint(n))), b), n+1)
+	return api.Sub(1, bits[n])
+}
+
+// NativeLessThanBits is the reference of LessThanBits, it fails like the circuit when an operand isn't n bits
+func NativeLessThanBits(a, b *big.Int, n int) (bool, error) {
+	if err := nativeCheckBits(n, a, b); err != nil {
+		return false, err
+	}
+	return a.Cmp(b) < 0, nil
+}
+
+// NativeLessEqThanBits is the reference of LessEqThanBits
+func NativeLessEqThanBits(a, b *big.Int, n int) (bool, error) {
+	if err := nativeCheckBits(n, a, b); err != nil {
+		return false, err
+	}
+	return a.Cmp(b) <= 0, nil
+}
+
+// NativeGreaterThanBits is the reference of GreaterThanBits
+func NativeGreaterThanBits(a, b *big.Int, n int) (bool, error) {
+	return NativeLessThanBits(b, a, n)
+}
+
+// NativeGreaterEqThanBits is the reference of GreaterEqThanBits
+func NativeGreaterEqThanBits(a, b *big.Int, n int) (bool, error) {
+	return NativeLessEqThanBits(b, a, n)
+}
+
+// NativeInRange is the reference of AssertInRange, it returns false where the circuit is not satisfied
+func NativeInRange(x, lo, hi *big.Int, n int) (bool, error) {
+	if err := nativeCheckBits(n, lo, hi); err != nil {
+		return false, err
+	}
+	return x.Sign() >= 0 && x.Cmp(lo) >= 0 && x.Cmp(hi) <= 0, nil
+}
+
+func nativeCheckBits(n int, values ...*big.Int) error {
+	if n < 1 || n > MaxComparatorBits {
+		return fmt.Errorf("%d bits operands, must be in [1, %d]", n, MaxComparatorBits)
+	}
+	for _, v := range values {
+		if v.Sign() < 0 || v.BitLen() > n {
+			return fmt.Errorf("%s is not a %d bits value", v, n)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package circuits
+
+import (
+	"crypto/rand"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"math/big"
+	"testing"
+)
+
+type comparatorsBitsCircuit struct {
+	A, B           frontend.Variable
+	Lt, Le, Gt, Ge frontend.Variable
+	n              int
+}
+
+func (c *comparatorsBitsCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(LessThanBits(api, c.A, c.B, c.n), c.Lt)
+	api.AssertIsEqual(LessEqThanBits(api, c.A, c.B, c.n), c.Le)
+	api.AssertIsEqual(GreaterThanBits(api, c.A, c.B, c.n), c.Gt)
+	api.AssertIsEqual(GreaterEqThanBits(api, c.A, c.B, c.n), c.Ge)
+	return nil
+}
+
+type inRangeCircuit struct {
+	X, Lo, Hi frontend.Variable
+	n         int
+}
+
+func (c *inRangeCircuit) Define(api frontend.API) error {
+	AssertInRange(api, c.X, c.Lo, c.Hi, c.n)
+	return nil
+}
+
+func bitsOperands(t *testing.T, n int) []*big.Int {
+	max := new(big.Int).Lsh(big.NewInt(1), uint(n))
+	operands := []*big.Int{big.NewInt(0), new(big.Int).Sub(max, big.NewInt(1))}
+	for i := 0; i < 4; i++ {
+		r, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			t.Fatal(err)
+		}
+		operands = append(operands, r)
+	}
+	return operands
+}
+
+func toBit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestComparatorsBitsDifferential(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, n := range []int{1, 8, 64, MaxComparatorBits} {
+		operands := bitsOperands(t, n)
+		for _, a := range operands {
+			for _, b := range operands {
+				lt, err := NativeLessThanBits(a, b, n)
+				assert.NoError(err)
+				le, _ := NativeLessEqThanBits(a, b, n)
+				gt, _ := NativeGreaterThanBits(a, b, n)
+				ge, _ := NativeGreaterEqThanBits(a, b, n)
+				witness := &comparatorsBitsCircuit{A: a, B: b, Lt: toBit(lt), Le: toBit(le), Gt: toBit(gt), Ge: toBit(ge)}
+				assert.NoError(test.IsSolved(&comparatorsBitsCircuit{n: n}, witness, ecc.BN254, backend.GROTH16), "%d bits: %s, %s", n, a, b)
+
+				witness.Lt = 1 - toBit(lt)
+				assert.Error(test.IsSolved(&comparatorsBitsCircuit{n: n}, witness, ecc.BN254, backend.GROTH16))
+			}
+		}
+
+		// an operand of n+1 bits is rejected by both
+		tooLarge := new(big.Int).Lsh(big.NewInt(1), uint(n))
+		_, err := NativeLessThanBits(big.NewInt(0), tooLarge, n)
+		assert.Error(err)
+		witness := &comparatorsBitsCircuit{A: 0, B: tooLarge, Lt: 1, Le: 1, Gt: 0, Ge: 0}
+		assert.Error(test.IsSolved(&comparatorsBitsCircuit{n: n}, witness, ecc.BN254, backend.GROTH16))
+	}
+}
+
+func TestAssertInRangeDifferential(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, n := range []int{8, 64} {
+		operands := bitsOperands(t, n)
+		lo, hi := operands[2], operands[3]
+		if lo.Cmp(hi) > 0 {
+			lo, hi = hi, lo
+		}
+		xs := append(operands, lo, hi, new(big.Int).Sub(lo, big.NewInt(1)), new(big.Int).Add(hi, big.NewInt(1)))
+		for _, x := range xs {
+			in, err := NativeInRange(x, lo, hi, n)
+			assert.NoError(err)
+			err = test.IsSolved(&inRangeCircuit{n: n}, &inRangeCircuit{X: x, Lo: lo, Hi: hi}, ecc.BN254, backend.GROTH16)
+			if in {
+				assert.NoError(err, "%s in [%s, %s]", x, lo, hi)
+			} else {
+				assert.Error(err, "%s not in [%s, %s]", x, lo, hi)
+			}
+		}
+	}
+}
+
+type lessThanCircuit struct {
+	A, B frontend.Variable
+	n    int
+}
+
+func (c *lessThanCircuit) Define(api frontend.API) error {
+	if c.n == 0 {
+		api.AssertIsEqual(LessThan(api, c.A, c.B), 1)
+	} else {
+		api.AssertIsEqual(LessThanBits(api, c.A, c.B, c.n), 1)
+	}
+	return nil
+}
+
+type rangeCheckCircuit struct {
+	X frontend.Variable
+	n int
+}
+
+func (c *rangeCheckCircuit) Define(api frontend.API) error {
+	RangeCheck(api, c.X, c.n)
+	return nil
+}
+
+func TestComparatorsBitsConstraints(t *testing.T) {
+	assert := test.NewAssert(t)
+	nbConstraints := func(circuit frontend.Circuit) int {
+		ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, circuit)
+		assert.NoError(err)
+		return ccs.GetNbConstraints()
+	}
+
+	for _, n := range []int{8, 64, 128} {
+		assert.Equal(n+1, nbConstraints(&rangeCheckCircuit{n: n}), "range check %d bits", n)
+		// two range checks, one n+1 bits decomposition and the assertion
+		assert.Equal(3*(n+1)+1+1, nbConstraints(&lessThanCircuit{n: n}), "less than %d bits", n)
+	}
+	full := nbConstraints(&lessThanCircuit{})
+	bounded := nbConstraints(&lessThanCircuit{n: 64})
+	t.Logf("LessThan: %d constraints, LessThanBits(64): %d constraints", full, bounded)
+	assert.True(bounded < full/2)
+}
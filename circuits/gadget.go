@@ -0,0 +1,31 @@
+package circuits
+
+import "github.com/consensys/gnark/frontend"
+
+// Gadget names of the per-gadget constraint breakdown
+const (
+	GadgetPoseidon   = "poseidon"
+	GadgetMiMC       = "mimc"
+	GadgetMerkle     = "merkle"
+	GadgetComparator = "comparator"
+	GadgetKeccak     = "keccak"
+	GadgetSha256     = "sha256"
+	GadgetPedersen   = "pedersen"
+)
+
+// GadgetRecorder is implemented by the APIs that attribute constraints to gadgets (zk.NewCircuitStats).
+// Gadgets nest: the constraints of an inner gadget are only counted in the inner gadget.
+type GadgetRecorder interface {
+	BeginGadget(name string)
+	EndGadget(name string)
+}
+
+// Gadget calls fn as one call of the gadget name. It doesn't add any constraint, and only calls fn when the api
+// doesn't record gadgets.
+func Gadget(api frontend.API, name string, fn func()) {
+	if r, ok := api.(GadgetRecorder); ok {
+		r.BeginGadget(name)
+		defer r.EndGadget(name)
+	}
+	fn()
+}
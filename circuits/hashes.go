@@ -0,0 +1,281 @@
+package circuits
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark/frontend"
+	gnarkHash "github.com/consensys/gnark/std/hash"
+	gnarkMiMC "github.com/consensys/gnark/std/hash/mimc"
+	"golang.org/x/crypto/sha3"
+	"hash"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+// HashID identifies a hash of the registry: a tree (merkle.NewMerkleTreeBytesHash), a circuit and a bidding room
+// configured with the same HashID hash the same values.
+type HashID string
+
+const (
+	HashMiMC     HashID = "mimc"
+	HashPoseidon HashID = "poseidon"
+	// HashKeccak256 is keccak256 of the 32 bytes of each element reduced modulo r, with the leaves and the pairs of
+	// a node in proof order. It is not the hash of the trees of merkle.NewMerkleTreeBytes, verified by
+	// solidity/MerkleProof.sol and circuits.VerifyKeccakMerkleProof: they keep the 32 bytes of the digest and sort the
+	// pairs, so the roots differ.
+	HashKeccak256 HashID = "keccak256"
+	// HashSha256 is sha256 of the 32 bytes of each element reduced modulo r
+	HashSha256 HashID = "sha256"
+)
+
+// HashFunction pairs a native hash with the gadget computing the same values in a circuit. Both hash field elements:
+// the native hash splits its data in 32 bytes big-endian elements (the last one may be shorter, the empty data is one
+// zero element) reduced modulo r, and Sum appends the 32 big-endian bytes of the element returned by the Sum of the
+// gadget. The gadget is a gnark hash whose Sum empties the data.
+type HashFunction struct {
+	// Gadget is the name of the gadget in the constraint breakdown
+	Gadget string
+	// New returns the native hash
+	New func() hash.Hash
+	// NewGadget returns the in-circuit hash
+	NewGadget func(api frontend.API) (gnarkHash.Hash, error)
+}
+
+var hashRegistry = struct {
+	sync.RWMutex
+	hashes map[HashID]HashFunction
+}{hashes: make(map[HashID]HashFunction)}
+
+func init() {
+	mustRegisterHash(HashMiMC, HashFunction{
+		Gadget: GadgetMiMC,
+		New:    mimc.NewMiMC,
+		NewGadget: func(api frontend.API) (gnarkHash.Hash, error) {
+			h, err := gnarkMiMC.NewMiMC(api)
+			return &mimcHasher{MiMC: h}, err
+		},
+	})
+	mustRegisterHash(HashPoseidon, HashFunction{
+		Gadget: GadgetPoseidon,
+		New:    NewPoseidonHash,
+		NewGadget: func(api frontend.API) (gnarkHash.Hash, error) {
+			return NewPoseidonHasher(api), nil
+		},
+	})
+	mustRegisterHash(HashKeccak256, HashFunction{
+		Gadget: GadgetKeccak,
+		New: func() hash.Hash {
+			return newBytesHash(sha3.NewLegacyKeccak256)
+		},
+		NewGadget: func(api frontend.API) (gnarkHash.Hash, error) {
+			return &bytesHasher{api: api, digest: Keccak256}, nil
+		},
+	})
+	mustRegisterHash(HashSha256, HashFunction{
+		Gadget: GadgetSha256,
+		New: func() hash.Hash {
+			return newBytesHash(sha256.New)
+		},
+		NewGadget: func(api frontend.API) (gnarkHash.Hash, error) {
+			return &bytesHasher{api: api, digest: Sha256}, nil
+		},
+	})
+}
+
+// RegisterHash registers a hash, the id must not be registered yet: the trees, circuits and keys of an id stay
+// consistent with each other
+func RegisterHash(id HashID, h HashFunction) error {
+	if id == "" || h.New == nil || h.NewGadget == nil {
+		return fmt.Errorf("hash %q needs an id, New and NewGadget", id)
+	}
+	hashRegistry.Lock()
+	defer hashRegistry.Unlock()
+	if _, ok := hashRegistry.hashes[id]; ok {
+		return fmt.Errorf("hash %q is already registered", id)
+	}
+	hashRegistry.hashes[id] = h
+	return nil
+}
+
+func mustRegisterHash(id HashID, h HashFunction) {
+	if err := RegisterHash(id, h); err != nil {
+		panic(err)
+	}
+}
+
+// LookupHash returns a registered hash
+func LookupHash(id HashID) (HashFunction, error) {
+	hashRegistry.RLock()
+	defer hashRegistry.RUnlock()
+	h, ok := hashRegistry.hashes[id]
+	if !ok {
+		return HashFunction{}, fmt.Errorf("unknown hash %q", id)
+	}
+	return h, nil
+}
+
+// HashIDs returns the sorted ids of the registered hashes
+func HashIDs() []HashID {
+	hashRegistry.RLock()
+	defer hashRegistry.RUnlock()
+	ids := make([]HashID, 0, len(hashRegistry.hashes))
+	for id := range hashRegistry.hashes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// VerifyMerkleProof is merkle.VerifyProof of gnark with any hash: proofSet[0] is the leaf, hashed with h(leaf), and
+// the nodes are h(left, right), where helper[i-1] is 1 when proofSet[i] is the right child. The proof is built by
+// merkle.Tree.BuilderProofHelper with the native hash of h.
+func VerifyMerkleProof(api frontend.API, h gnarkHash.Hash, merkleRoot frontend.Variable, proofSet, helper []frontend.Variable) {
+	Gadget(api, GadgetMerkle, func() {
+		h.Reset()
+		h.Write(proofSet[0])
+		sum := h.Sum()
+		for i := 1; i < len(proofSet); i++ {
+			api.AssertIsBoolean(helper[i-1])
+			h.Reset()
+			h.Write(api.Select(helper[i-1], sum, proofSet[i]), api.Select(helper[i-1], proofSet[i], sum))
+			sum = h.Sum()
+		}
+		api.AssertIsEqual(sum, merkleRoot)
+	})
+}
+
+// mimcHasher is the MiMC of gnark hashing a zero when nothing was written, like the native MiMC, and reset by Sum
+type mimcHasher struct {
+	gnarkMiMC.MiMC
+	written bool
+}
+
+func (h *mimcHasher) Write(data ...frontend.Variable) {
+	h.written = h.written || len(data) > 0
+	h.MiMC.Write(data...)
+}
+
+func (h *mimcHasher) Sum() frontend.Variable {
+	if !h.written {
+		h.MiMC.Write(0)
+	}
+	sum := h.MiMC.Sum()
+	h.Reset()
+	return sum
+}
+
+func (h *mimcHasher) Reset() {
+	h.MiMC.Reset()
+	h.written = false
+}
+
+// hashElements splits data in 32 bytes big-endian elements reduced modulo r, the last one may be shorter and the empty
+// data is one zero element
+func hashElements(data []byte) []fr.Element {
+	var elements []fr.Element
+	for len(data) > 0 || len(elements) == 0 {
+		n := len(data)
+		if n > fr.Bytes {
+			n = fr.Bytes
+		}
+		var e fr.Element
+		e.SetBytes(data[:n])
+		elements = append(elements, e)
+		data = data[n:]
+	}
+	return elements
+}
+
+// bytesHash is a byte-oriented hash of field elements: the digest of the 32 bytes of each element, reduced modulo r
+type bytesHash struct {
+	data []byte
+	new  func() hash.Hash
+}
+
+func newBytesHash(new func() hash.Hash) hash.Hash {
+	return &bytesHash{new: new}
+}
+
+func (d *bytesHash) Write(p []byte) (int, error) {
+	d.data = append(d.data, p...)
+	return len(p), nil
+}
+
+func (d *bytesHash) Sum(b []byte) []byte {
+	h := d.new()
+	for _, e := range hashElements(d.data) {
+		bytes := e.Bytes()
+		h.Write(bytes[:])
+	}
+	var sum fr.Element
+	sum.SetBytes(h.Sum(nil))
+	bytes := sum.Bytes()
+	return append(b, bytes[:]...)
+}
+
+func (d *bytesHash) Reset() {
+	d.data = nil
+}
+
+func (d *bytesHash) Size() int {
+	return fr.Bytes
+}
+
+func (d *bytesHash) BlockSize() int {
+	return fr.Bytes
+}
+
+// bytesHasher is the gadget of bytesHash: the digest of the 32 big-endian bytes of the variables, folded in an element
+type bytesHasher struct {
+	api    frontend.API
+	digest func(api frontend.API, mBytes []frontend.Variable) []frontend.Variable
+	data   []frontend.Variable
+}
+
+var _ gnarkHash.Hash = (*bytesHasher)(nil)
+
+func (h *bytesHasher) Write(data ...frontend.Variable) {
+	h.data = append(h.data, data...)
+}
+
+func (h *bytesHasher) Sum() frontend.Variable {
+	data := h.data
+	if len(data) == 0 {
+		data = []frontend.Variable{0}
+	}
+	var mBytes []frontend.Variable
+	for _, v := range data {
+		bytes := elementToBytes(h.api, v)
+		mBytes = append(mBytes, bytes[:]...)
+	}
+	// the big-endian digest, the field reduces it
+	var sum frontend.Variable = 0
+	for _, b := range h.digest(h.api, mBytes) {
+		sum = h.api.Add(h.api.Mul(sum, 256), b)
+	}
+	h.Reset()
+	return sum
+}
+
+func (h *bytesHasher) Reset() {
+	h.data = nil
+}
+
+// elementToBytes returns the 32 big-endian bytes of the canonical representation of v, smaller than r
+func elementToBytes(api frontend.API, v frontend.Variable) [fr.Bytes]frontend.Variable {
+	bits := append(api.ToBinary(v, fr.Bits), 0, 0)
+	le := bitsToBytes(api, bits)
+	var bytes, max [fr.Bytes]frontend.Variable
+	for i := range bytes {
+		bytes[i] = le[fr.Bytes-1-i]
+	}
+	// v <= r - 1, the decomposition of v + r would fit in the bits too
+	for i, b := range new(big.Int).Sub(fr.Modulus(), big.NewInt(1)).FillBytes(make([]byte, fr.Bytes)) {
+		max[i] = b
+	}
+	api.AssertIsEqual(lessEqBytes32(api, bytes, max), 1)
+	return bytes
+}
@@ -0,0 +1,116 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"math/big"
+	"testing"
+)
+
+type hashCircuit struct {
+	In   []frontend.Variable
+	Hash frontend.Variable `gnark:",public"`
+	id   HashID
+}
+
+func (c *hashCircuit) Define(api frontend.API) error {
+	hf, err := LookupHash(c.id)
+	if err != nil {
+		return err
+	}
+	h, err := hf.NewGadget(api)
+	if err != nil {
+		return err
+	}
+	// a second hash checks Sum empties the data
+	h.Write(1)
+	h.Reset()
+	h.Write(c.In...)
+	api.AssertIsEqual(h.Sum(), c.Hash)
+	return nil
+}
+
+func TestHashes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assert.Equal([]HashID{HashKeccak256, HashMiMC, HashPoseidon, HashSha256}, HashIDs())
+	_, err := LookupHash("md5")
+	assert.Error(err)
+
+	// the registered hashes can't be replaced
+	mimcHash, err := LookupHash(HashMiMC)
+	assert.NoError(err)
+	assert.Error(RegisterHash(HashMiMC, mimcHash))
+	assert.Error(RegisterHash("", mimcHash))
+	assert.Error(RegisterHash("md5", HashFunction{Gadget: GadgetMiMC}))
+	_, err = LookupHash("md5")
+	assert.Error(err)
+
+	rMinus1 := new(big.Int).Sub(fr.Modulus(), big.NewInt(1))
+	inputs := [][]*big.Int{
+		nil,
+		{big.NewInt(0)},
+		{big.NewInt(42), rMinus1},
+		{big.NewInt(1), big.NewInt(2), new(big.Int).Lsh(big.NewInt(1), 200)},
+	}
+	for _, id := range HashIDs() {
+		hf, err := LookupHash(id)
+		assert.NoError(err)
+		for _, in := range inputs {
+			// the native hash of the 32 bytes of each element
+			h := hf.New()
+			for _, v := range in {
+				_, err := h.Write(v.FillBytes(make([]byte, fr.Bytes)))
+				assert.NoError(err)
+			}
+			sum := h.Sum(nil)
+			assert.Equal(fr.Bytes, len(sum))
+
+			circuit := &hashCircuit{In: make([]frontend.Variable, len(in)), id: id}
+			witness := &hashCircuit{In: make([]frontend.Variable, len(in)), Hash: sum}
+			for i := range in {
+				witness.In[i] = in[i]
+			}
+			assert.NoError(test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16), "%s of %v", id, in)
+
+			witness.Hash = new(big.Int).Add(new(big.Int).SetBytes(sum), big.NewInt(1))
+			assert.Error(test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16), "%s of %v", id, in)
+		}
+	}
+
+	// a data shorter than an element is its big-endian value
+	for _, id := range HashIDs() {
+		hf, err := LookupHash(id)
+		assert.NoError(err)
+		short, long := hf.New(), hf.New()
+		short.Write([]byte{1, 2})
+		long.Write(big.NewInt(0x0102).FillBytes(make([]byte, fr.Bytes)))
+		assert.Equal(long.Sum(nil), short.Sum(nil), "%s", id)
+	}
+}
+
+func TestHashesCompiled(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// the byte hashes decompose the elements, the R1CS solver must agree with the test engine
+	in := []*big.Int{big.NewInt(7), new(big.Int).Sub(fr.Modulus(), big.NewInt(1))}
+	for _, id := range []HashID{HashKeccak256, HashSha256} {
+		hf, err := LookupHash(id)
+		assert.NoError(err)
+		h := hf.New()
+		for _, v := range in {
+			h.Write(v.FillBytes(make([]byte, fr.Bytes)))
+		}
+		witness := &hashCircuit{In: []frontend.Variable{in[0], in[1]}, Hash: h.Sum(nil)}
+
+		cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &hashCircuit{In: make([]frontend.Variable, len(in)), id: id})
+		assert.NoError(err)
+		full, err := frontend.NewWitness(witness, ecc.BN254)
+		assert.NoError(err)
+		assert.NoError(cs.IsSolved(full), "%s", id)
+	}
+}
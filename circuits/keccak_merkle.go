@@ -0,0 +1,29 @@
+package circuits
+
+import "github.com/consensys/gnark/frontend"
+
+// KeccakHashSize is the size in bytes of the keccak256 hashes of the keccak Merkle trees
+const KeccakHashSize = 32
+
+// VerifyKeccakMerkleProof asserts that leaf is in the tree of root, like MerkleProof.sol: the hashes are big-endian
+// 32-byte arrays and each proof element is hashed with the computed hash, keccak256(a‖b) of the pair sorted so that
+// a <= b. The trees are built by merkle.NewMerkleTreeBytes, the proof is its GetProof. The bytes of leaf and proof are
+// range checked by Keccak256, each level costs about as much as one keccak256 of 64 bytes.
+func VerifyKeccakMerkleProof(api frontend.API, root, leaf [KeccakHashSize]frontend.Variable, proof [][KeccakHashSize]frontend.Variable) {
+	Gadget(api, GadgetMerkle, func() {
+		computed := leaf
+		for _, element := range proof {
+			// computed <= element: computed‖element, otherwise element‖computed
+			ordered := lessEqBytes32(api, computed, element)
+			pair := make([]frontend.Variable, 2*KeccakHashSize)
+			for i := 0; i < KeccakHashSize; i++ {
+				pair[i] = api.Select(ordered, computed[i], element[i])
+				pair[KeccakHashSize+i] = api.Select(ordered, element[i], computed[i])
+			}
+			copy(computed[:], Keccak256(api, pair))
+		}
+		for i := range computed {
+			api.AssertIsEqual(computed[i], root[i])
+		}
+	})
+}
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/consensys/gnark/test"
 	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/sha3"
+	"math/big"
 	"math/rand"
 	"testing"
 	"time"
@@ -100,3 +103,382 @@ func Test_Keccak256(t *testing.T) {
 	internal, secret, public := _r1cs.GetNbVariables()
 	fmt.Printf("public, secret, internal %v, %v, %v\n", public, secret, internal)
 }
+
+type keccakFamilyCircuit struct {
+	M      []frontend.Variable
+	Length frontend.Variable
+	Hash   []frontend.Variable `gnark:",public"`
+	hash   int
+	varLen bool
+}
+
+func (c *keccakFamilyCircuit) Define(api frontend.API) error {
+	// the index of the hash in keccakFamily: gnark's test engine can't clone circuits with funcs
+	h := keccakFamily[c.hash]
+	var hash []frontend.Variable
+	if c.varLen {
+		hash = h.sumVar(api, c.M, c.Length)
+	} else {
+		hash = h.sum(api, c.M)
+	}
+	for i := range hash {
+		api.AssertIsEqual(hash[i], c.Hash[i])
+	}
+	return nil
+}
+
+type keccakFamilyHash struct {
+	name   string
+	rate   int
+	sum    func(api frontend.API, m []frontend.Variable) []frontend.Variable
+	sumVar func(api frontend.API, m []frontend.Variable, length frontend.Variable) []frontend.Variable
+	native func(m []byte) []byte
+}
+
+// the SHAKE outputs are longer than their rate
+var keccakFamily = []keccakFamilyHash{
+	{"keccak256", 136, Keccak256, Keccak256Var, func(m []byte) []byte { return crypto.Keccak256(m) }},
+	{"keccak512", 72, Keccak512, Keccak512Var, func(m []byte) []byte { return crypto.Keccak512(m) }},
+	{"sha3-256", 136, Sha3Sum256, Sha3Sum256Var, func(m []byte) []byte { h := sha3.Sum256(m); return h[:] }},
+	{"sha3-512", 72, Sha3Sum512, Sha3Sum512Var, func(m []byte) []byte { h := sha3.Sum512(m); return h[:] }},
+	{"shake128", 168,
+		func(api frontend.API, m []frontend.Variable) []frontend.Variable { return ShakeSum128(api, m, 200) },
+		func(api frontend.API, m []frontend.Variable, length frontend.Variable) []frontend.Variable {
+			return ShakeSum128Var(api, m, length, 200)
+		},
+		func(m []byte) []byte { h := make([]byte, 200); sha3.ShakeSum128(h, m); return h }},
+	{"shake256", 136,
+		func(api frontend.API, m []frontend.Variable) []frontend.Variable { return ShakeSum256(api, m, 200) },
+		func(api frontend.API, m []frontend.Variable, length frontend.Variable) []frontend.Variable {
+			return ShakeSum256Var(api, m, length, 200)
+		},
+		func(m []byte) []byte { h := make([]byte, 200); sha3.ShakeSum256(h, m); return h }},
+}
+
+func keccakFamilySolved(h int, m []byte, length int, varLen bool, hash []byte) error {
+	circuit := &keccakFamilyCircuit{M: make([]frontend.Variable, len(m)), Hash: make([]frontend.Variable, len(hash)), hash: h, varLen: varLen}
+	witness := &keccakFamilyCircuit{M: make([]frontend.Variable, len(m)), Length: length, Hash: make([]frontend.Variable, len(hash))}
+	for i := range m {
+		witness.M[i] = m[i]
+	}
+	for i := range hash {
+		witness.Hash[i] = hash[i]
+	}
+	return test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16)
+}
+
+func TestKeccakFamily(t *testing.T) {
+	assert := test.NewAssert(t)
+	rng := rand.New(rand.NewSource(1))
+
+	for i, h := range keccakFamily {
+		// around the block boundary: a message of rate bytes takes a whole block of padding
+		for _, length := range []int{0, 1, h.rate - 1, h.rate, h.rate + 1} {
+			m := make([]byte, length)
+			rng.Read(m)
+			hash := h.native(m)
+			assert.NoError(keccakFamilySolved(i, m, length, false, hash), "%s, %d bytes", h.name, length)
+		}
+
+		// the messages of up to rate+1 bytes, the bytes after length are ignored
+		m := make([]byte, h.rate+1)
+		rng.Read(m)
+		for _, length := range []int{0, h.rate - 1, h.rate, h.rate + 1} {
+			hash := h.native(m[:length])
+			assert.NoError(keccakFamilySolved(i, m, length, true, hash), "%s, %d of %d bytes", h.name, length, len(m))
+		}
+		hash := h.native(m[:1])
+		assert.Error(keccakFamilySolved(i, m, 2, true, hash), "%s, wrong length", h.name)
+		assert.Error(keccakFamilySolved(i, m, len(m)+1, true, h.native(m)), "%s, length out of range", h.name)
+	}
+}
+
+// keccakFPacked is the former Keccak-f[1600] on 64-bit lanes, which decomposes and recomposes the lanes at every
+// operation: the reference of the constraint count and of the outputs of keccakF.
+
+func init() {
+	hint.Register(divFunc)
+}
+
+func u64Xor(api frontend.API, a frontend.Variable, b frontend.Variable, cs ...frontend.Variable) frontend.Variable {
+	bitsA := api.ToBinary(a, 64)
+	bitsB := api.ToBinary(b, 64)
+	bitsRes := make([]frontend.Variable, 64)
+	for i := 0; i < 64; i++ {
+		bitsRes[i] = api.Xor(bitsA[i], bitsB[i])
+	}
+
+	for _, c := range cs {
+		bitsC := api.ToBinary(c)
+		for i := 0; i < 64; i++ {
+			bitsRes[i] = api.Xor(bitsRes[i], bitsC[i])
+		}
+	}
+	return api.FromBinary(bitsRes...)
+}
+
+func u64And(api frontend.API, a frontend.Variable, b frontend.Variable, cs ...frontend.Variable) frontend.Variable {
+	bitsA := api.ToBinary(a, 64)
+	bitsB := api.ToBinary(b, 64)
+	bitsRes := make([]frontend.Variable, 64)
+	for i := 0; i < 64; i++ {
+		bitsRes[i] = api.And(bitsA[i], bitsB[i])
+	}
+
+	for _, c := range cs {
+		bitsC := api.ToBinary(c)
+		for i := 0; i < 64; i++ {
+			bitsRes[i] = api.And(bitsRes[i], bitsC[i])
+		}
+	}
+
+	return api.FromBinary(bitsRes...)
+}
+
+func u64Or(api frontend.API, a frontend.Variable, b frontend.Variable, cs ...frontend.Variable) frontend.Variable {
+	bitsA := api.ToBinary(a, 64)
+	bitsB := api.ToBinary(b, 64)
+	bitsRes := make([]frontend.Variable, 64)
+	for i := 0; i < 64; i++ {
+		bitsRes[i] = api.Or(bitsA[i], bitsB[i])
+	}
+
+	for _, c := range cs {
+		bitsC := api.ToBinary(c)
+		for i := 0; i < 64; i++ {
+			bitsRes[i] = api.Or(bitsRes[i], bitsC[i])
+		}
+	}
+
+	return api.FromBinary(bitsRes...)
+}
+
+func pow2(n uint) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), n)
+}
+
+func fixedToU64(api frontend.API, a frontend.Variable) frontend.Variable {
+	return api.FromBinary(api.ToBinary(a, 128)[:64]...)
+}
+
+func divFunc(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	a := inputs[0]
+	b := inputs[1]
+	outputs[0], outputs[1] = new(big.Int).QuoRem(a, b, new(big.Int))
+	return nil
+}
+
+func div(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	outputs, _ := api.Compiler().NewHint(divFunc, 2, a, b)
+	api.AssertIsEqual(a, api.Add(api.Mul(b, outputs[0]), outputs[1]))
+	return outputs[0]
+}
+
+func keccakFPacked(api frontend.API, a []frontend.Variable) []frontend.Variable {
+	var b [25]frontend.Variable
+	for i := 0; i < len(b); i++ {
+		b[i] = 0
+	}
+	var c [5]frontend.Variable
+	for i := 0; i < len(c); i++ {
+		c[i] = 0
+	}
+	var d [5]frontend.Variable
+	for i := 0; i < len(d); i++ {
+		d[i] = 0
+	}
+	var rc [24]frontend.Variable
+	rc[0], _ = new(big.Int).SetString("0000000000000001", 16)
+	rc[1], _ = new(big.Int).SetString("0000000000008082", 16)
+	rc[2], _ = new(big.Int).SetString("800000000000808A", 16)
+	rc[3], _ = new(big.Int).SetString("8000000080008000", 16)
+	rc[4], _ = new(big.Int).SetString("000000000000808B", 16)
+	rc[5], _ = new(big.Int).SetString("0000000080000001", 16)
+	rc[6], _ = new(big.Int).SetString("8000000080008081", 16)
+	rc[7], _ = new(big.Int).SetString("8000000000008009", 16)
+	rc[8], _ = new(big.Int).SetString("000000000000008A", 16)
+	rc[9], _ = new(big.Int).SetString("0000000000000088", 16)
+	rc[10], _ = new(big.Int).SetString("0000000080008009", 16)
+	rc[11], _ = new(big.Int).SetString("000000008000000A", 16)
+	rc[12], _ = new(big.Int).SetString("000000008000808B", 16)
+	rc[13], _ = new(big.Int).SetString("800000000000008B", 16)
+	rc[14], _ = new(big.Int).SetString("8000000000008089", 16)
+	rc[15], _ = new(big.Int).SetString("8000000000008003", 16)
+	rc[16], _ = new(big.Int).SetString("8000000000008002", 16)
+	rc[17], _ = new(big.Int).SetString("8000000000000080", 16)
+	rc[18], _ = new(big.Int).SetString("000000000000800A", 16)
+	rc[19], _ = new(big.Int).SetString("800000008000000A", 16)
+	rc[20], _ = new(big.Int).SetString("8000000080008081", 16)
+	rc[21], _ = new(big.Int).SetString("8000000000008080", 16)
+	rc[22], _ = new(big.Int).SetString("0000000080000001", 16)
+	rc[23], _ = new(big.Int).SetString("8000000080008008", 16)
+
+	mask := new(big.Int).Sub(pow2(64), big.NewInt(1))
+
+	for i := 0; i < 24; i++ {
+		c[0] = u64Xor(api, a[0], a[1], a[2], a[3], a[4])
+		c[1] = u64Xor(api, a[5], a[6], a[7], a[8], a[9])
+		c[2] = u64Xor(api, a[10], a[11], a[12], a[13], a[14])
+		c[3] = u64Xor(api, a[15], a[16], a[17], a[18], a[19])
+		c[4] = u64Xor(api, a[20], a[21], a[22], a[23], a[24])
+
+		pow63 := pow2(63)
+
+		//api.Println(c[1])
+		//api.Println(api.Div(c[1], pow63))
+
+		d[0] = u64Xor(api, c[4], u64Or(api, fixedToU64(api, api.Mul(c[1], 2)), div(api, c[1], pow63)))
+		d[1] = u64Xor(api, c[0], u64Or(api, fixedToU64(api, api.Mul(c[2], 2)), div(api, c[2], pow63)))
+		d[2] = u64Xor(api, c[1], u64Or(api, fixedToU64(api, api.Mul(c[3], 2)), div(api, c[3], pow63)))
+		d[3] = u64Xor(api, c[2], u64Or(api, fixedToU64(api, api.Mul(c[4], 2)), div(api, c[4], pow63)))
+		d[4] = u64Xor(api, c[3], u64Or(api, fixedToU64(api, api.Mul(c[0], 2)), div(api, c[0], pow63)))
+
+		a[0] = u64Xor(api, a[0], d[0])
+		a[1] = u64Xor(api, a[1], d[0])
+		a[2] = u64Xor(api, a[2], d[0])
+		a[3] = u64Xor(api, a[3], d[0])
+		a[4] = u64Xor(api, a[4], d[0])
+
+		a[5] = u64Xor(api, a[5], d[1])
+		a[6] = u64Xor(api, a[6], d[1])
+		a[7] = u64Xor(api, a[7], d[1])
+		a[8] = u64Xor(api, a[8], d[1])
+		a[9] = u64Xor(api, a[9], d[1])
+
+		a[10] = u64Xor(api, a[10], d[2])
+		a[11] = u64Xor(api, a[11], d[2])
+		a[12] = u64Xor(api, a[12], d[2])
+		a[13] = u64Xor(api, a[13], d[2])
+		a[14] = u64Xor(api, a[14], d[2])
+
+		a[15] = u64Xor(api, a[15], d[3])
+		a[16] = u64Xor(api, a[16], d[3])
+		a[17] = u64Xor(api, a[17], d[3])
+		a[18] = u64Xor(api, a[18], d[3])
+		a[19] = u64Xor(api, a[19], d[3])
+
+		a[20] = u64Xor(api, a[20], d[4])
+		a[21] = u64Xor(api, a[21], d[4])
+		a[22] = u64Xor(api, a[22], d[4])
+		a[23] = u64Xor(api, a[23], d[4])
+		a[24] = u64Xor(api, a[24], d[4])
+
+		/*Rho and pi steps*/
+		b[0] = a[0]
+
+		b[8] = u64Or(api, fixedToU64(api, api.Mul(a[1], pow2(36))), div(api, a[1], pow2(28)))
+		b[11] = u64Or(api, fixedToU64(api, api.Mul(a[2], pow2(3))), div(api, a[2], pow2(61)))
+		b[19] = u64Or(api, fixedToU64(api, api.Mul(a[3], pow2(41))), div(api, a[3], pow2(23)))
+		b[22] = u64Or(api, fixedToU64(api, api.Mul(a[4], pow2(18))), div(api, a[4], pow2(46)))
+
+		b[2] = u64Or(api, fixedToU64(api, api.Mul(a[5], pow2(1))), div(api, a[5], pow2(63)))
+		b[5] = u64Or(api, fixedToU64(api, api.Mul(a[6], pow2(44))), div(api, a[6], pow2(20)))
+		b[13] = u64Or(api, fixedToU64(api, api.Mul(a[7], pow2(10))), div(api, a[7], pow2(54)))
+		b[16] = u64Or(api, fixedToU64(api, api.Mul(a[8], pow2(45))), div(api, a[8], pow2(19)))
+		b[24] = u64Or(api, fixedToU64(api, api.Mul(a[9], pow2(2))), div(api, a[9], pow2(62)))
+
+		b[4] = u64Or(api, fixedToU64(api, api.Mul(a[10], pow2(62))), div(api, a[10], pow2(2)))
+		b[7] = u64Or(api, fixedToU64(api, api.Mul(a[11], pow2(6))), div(api, a[11], pow2(58)))
+		b[10] = u64Or(api, fixedToU64(api, api.Mul(a[12], pow2(43))), div(api, a[12], pow2(21)))
+		b[18] = u64Or(api, fixedToU64(api, api.Mul(a[13], pow2(15))), div(api, a[13], pow2(49)))
+		b[21] = u64Or(api, fixedToU64(api, api.Mul(a[14], pow2(61))), div(api, a[14], pow2(3)))
+
+		b[1] = u64Or(api, fixedToU64(api, api.Mul(a[15], pow2(28))), div(api, a[15], pow2(36)))
+		b[9] = u64Or(api, fixedToU64(api, api.Mul(a[16], pow2(55))), div(api, a[16], pow2(9)))
+		b[12] = u64Or(api, fixedToU64(api, api.Mul(a[17], pow2(25))), div(api, a[17], pow2(39)))
+		b[15] = u64Or(api, fixedToU64(api, api.Mul(a[18], pow2(21))), div(api, a[18], pow2(43)))
+		b[23] = u64Or(api, fixedToU64(api, api.Mul(a[19], pow2(56))), div(api, a[19], pow2(8)))
+
+		b[3] = u64Or(api, fixedToU64(api, api.Mul(a[20], pow2(27))), div(api, a[20], pow2(37)))
+		b[6] = u64Or(api, fixedToU64(api, api.Mul(a[21], pow2(20))), div(api, a[21], pow2(44)))
+		b[14] = u64Or(api, fixedToU64(api, api.Mul(a[22], pow2(39))), div(api, a[22], pow2(25)))
+		b[17] = u64Or(api, fixedToU64(api, api.Mul(a[23], pow2(8))), div(api, a[23], pow2(56)))
+		b[20] = u64Or(api, fixedToU64(api, api.Mul(a[24], pow2(14))), div(api, a[24], pow2(50)))
+
+		/*Xi state*/
+
+		a[0] = u64Xor(api, b[0], u64And(api, u64Xor(api, b[5], mask), b[10]))
+		a[1] = u64Xor(api, b[1], u64And(api, u64Xor(api, b[6], mask), b[11]))
+		a[2] = u64Xor(api, b[2], u64And(api, u64Xor(api, b[7], mask), b[12]))
+		a[3] = u64Xor(api, b[3], u64And(api, u64Xor(api, b[8], mask), b[13]))
+		a[4] = u64Xor(api, b[4], u64And(api, u64Xor(api, b[9], mask), b[14]))
+
+		a[5] = u64Xor(api, b[5], u64And(api, u64Xor(api, b[10], mask), b[15]))
+		a[6] = u64Xor(api, b[6], u64And(api, u64Xor(api, b[11], mask), b[16]))
+		a[7] = u64Xor(api, b[7], u64And(api, u64Xor(api, b[12], mask), b[17]))
+		a[8] = u64Xor(api, b[8], u64And(api, u64Xor(api, b[13], mask), b[18]))
+		a[9] = u64Xor(api, b[9], u64And(api, u64Xor(api, b[14], mask), b[19]))
+
+		a[10] = u64Xor(api, b[10], u64And(api, u64Xor(api, b[15], mask), b[20]))
+		a[11] = u64Xor(api, b[11], u64And(api, u64Xor(api, b[16], mask), b[21]))
+		a[12] = u64Xor(api, b[12], u64And(api, u64Xor(api, b[17], mask), b[22]))
+		a[13] = u64Xor(api, b[13], u64And(api, u64Xor(api, b[18], mask), b[23]))
+		a[14] = u64Xor(api, b[14], u64And(api, u64Xor(api, b[19], mask), b[24]))
+
+		a[15] = u64Xor(api, b[15], u64And(api, u64Xor(api, b[20], mask), b[0]))
+		a[16] = u64Xor(api, b[16], u64And(api, u64Xor(api, b[21], mask), b[1]))
+		a[17] = u64Xor(api, b[17], u64And(api, u64Xor(api, b[22], mask), b[2]))
+		a[18] = u64Xor(api, b[18], u64And(api, u64Xor(api, b[23], mask), b[3]))
+		a[19] = u64Xor(api, b[19], u64And(api, u64Xor(api, b[24], mask), b[4]))
+
+		a[20] = u64Xor(api, b[20], u64And(api, u64Xor(api, b[0], mask), b[5]))
+		a[21] = u64Xor(api, b[21], u64And(api, u64Xor(api, b[1], mask), b[6]))
+		a[22] = u64Xor(api, b[22], u64And(api, u64Xor(api, b[2], mask), b[7]))
+		a[23] = u64Xor(api, b[23], u64And(api, u64Xor(api, b[3], mask), b[8]))
+		a[24] = u64Xor(api, b[24], u64And(api, u64Xor(api, b[4], mask), b[9]))
+
+		///*Last step*/
+
+		a[0] = u64Xor(api, a[0], rc[i])
+	}
+
+	return a
+}
+
+type keccakFCircuit struct {
+	State  [25]frontend.Variable
+	packed bool
+}
+
+func (c *keccakFCircuit) Define(api frontend.API) error {
+	var s keccakState
+	for l := range s {
+		copy(s[l][:], api.ToBinary(c.State[l], 64))
+	}
+	s = keccakF(api, s)
+	if !c.packed {
+		return nil
+	}
+	packed := keccakFPacked(api, append([]frontend.Variable{}, c.State[:]...))
+	for l := range s {
+		api.AssertIsEqual(api.FromBinary(s[l][:]...), packed[l])
+	}
+	return nil
+}
+
+func TestKeccakFConstraints(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// same permutation as the packed one
+	rng := rand.New(rand.NewSource(1))
+	var witness keccakFCircuit
+	for l := range witness.State {
+		witness.State[l] = rng.Uint64()
+	}
+	assert.NoError(test.IsSolved(&keccakFCircuit{packed: true}, &witness, ecc.BN254, backend.GROTH16))
+
+	bits, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &keccakFCircuit{}, frontend.IgnoreUnconstrainedInputs())
+	assert.NoError(err)
+	both, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &keccakFCircuit{packed: true}, frontend.IgnoreUnconstrainedInputs())
+	assert.NoError(err)
+	// the R1CS solver agrees with the test engine
+	w, err := frontend.NewWitness(&witness, ecc.BN254)
+	assert.NoError(err)
+	assert.NoError(both.IsSolved(w))
+
+	nbBits := bits.GetNbConstraints()
+	nbPacked := both.GetNbConstraints() - nbBits
+	t.Logf("keccak-f[1600]: %d constraints on bits, %d on packed lanes", nbBits, nbPacked)
+	assert.Less(nbBits*4, nbPacked)
+	assert.Less(nbBits, 160000)
+}
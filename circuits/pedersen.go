@@ -0,0 +1,59 @@
+package circuits
+
+import (
+	edwardsBN254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	edwards "github.com/consensys/gnark/std/algebra/twistededwards"
+	"math/big"
+)
+
+// PedersenValueBits is the size of the values committed in a circuit: their decomposition is unique, and the sum of
+// 2¹⁸⁶ of them still fits in the order of the curve, so totals open like single commitments
+const PedersenValueBits = 64
+
+// PedersenCommit returns the Pedersen commitment value·G + blinding·H on the twisted Edwards curve of BN254 (the curve
+// of the EdDSA keys of zk/account), G and H being PedersenGenerators. value is range checked on PedersenValueBits,
+// blinding may be any element: it is decomposed on all the bits of the field, a blinding b ≥ ℓ commits like b mod ℓ.
+// NativePedersenCommit computes the same point.
+func PedersenCommit(api frontend.API, value, blinding frontend.Variable) (c edwards.Point) {
+	Gadget(api, GadgetPedersen, func() {
+		c = pedersenCommit(api, value, blinding)
+	})
+	return c
+}
+
+func pedersenCommit(api frontend.API, value, blinding frontend.Variable) edwards.Point {
+	curve, err := edwards.NewEdCurve(api, twistededwards.BN254)
+	if err != nil {
+		// BN254 has a twisted Edwards curve
+		panic(err)
+	}
+	g, h := PedersenGenerators()
+	var identity, gh edwardsBN254.PointAffine
+	identity.Y.SetOne()
+	gh.Add(&g, &h)
+	// the points selected by the bits of value and blinding: O, G, H, G + H
+	var xs, ys [4]*big.Int
+	for i, p := range []edwardsBN254.PointAffine{identity, g, h, gh} {
+		xs[i], ys[i] = p.X.ToBigIntRegular(new(big.Int)), p.Y.ToBigIntRegular(new(big.Int))
+	}
+
+	valueBits := api.ToBinary(value, PedersenValueBits)
+	blindingBits := api.ToBinary(blinding)
+
+	// double and add on both scalars at once, from the most significant bit
+	res := edwards.Point{X: 0, Y: 1}
+	for i := len(blindingBits) - 1; i >= 0; i-- {
+		var vb frontend.Variable = 0
+		if i < len(valueBits) {
+			vb = valueBits[i]
+		}
+		res = curve.Double(res)
+		res = curve.Add(res, edwards.Point{
+			X: api.Lookup2(vb, blindingBits[i], xs[0], xs[1], xs[2], xs[3]),
+			Y: api.Lookup2(vb, blindingBits[i], ys[0], ys[1], ys[2], ys[3]),
+		})
+	}
+	return res
+}
@@ -0,0 +1,108 @@
+package circuits
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"math/big"
+	"sync"
+)
+
+// pedersenSeed derives H, nobody knows its discrete logarithm in base G
+const pedersenSeed = "gnark-bid/pedersen/H"
+
+var pedersenGenerators struct {
+	once sync.Once
+	g, h twistededwards.PointAffine
+}
+
+// PedersenGenerators returns the generators of the Pedersen commitments: G is the base point of the twisted Edwards
+// curve of BN254, H is hashed to the curve from a seed (try and increment on sha256(seed ‖ counter), then the cofactor
+// is cleared), both generate the subgroup of order ℓ
+func PedersenGenerators() (g, h twistededwards.PointAffine) {
+	pedersenGenerators.once.Do(func() {
+		curve := twistededwards.GetEdwardsCurve()
+		pedersenGenerators.g = curve.Base
+		pedersenGenerators.h = hashToEdwards(pedersenSeed)
+	})
+	return pedersenGenerators.g, pedersenGenerators.h
+}
+
+// hashToEdwards returns the first point (x, y) of the subgroup, y = sha256(seed ‖ counter) cleared of the cofactor
+func hashToEdwards(seed string) twistededwards.PointAffine {
+	curve := twistededwards.GetEdwardsCurve()
+	cofactor := curve.Cofactor.ToBigIntRegular(new(big.Int))
+	for counter := 0; ; counter++ {
+		digest := sha256.Sum256(append([]byte(seed), byte(counter)))
+		var y, y2, num, den, x2, one fr.Element
+		one.SetOne()
+		y.SetBytes(digest[:])
+		// a·x² + y² = 1 + d·x²·y²: x² = (1 - y²) / (a - d·y²)
+		y2.Square(&y)
+		num.Sub(&one, &y2)
+		den.Mul(&curve.D, &y2).Sub(&curve.A, &den)
+		if den.IsZero() {
+			continue
+		}
+		x2.Div(&num, &den)
+		var p twistededwards.PointAffine
+		if p.X.Sqrt(&x2) == nil {
+			continue
+		}
+		p.Y = y
+		p.ScalarMul(&p, cofactor)
+		if !p.IsZero() {
+			return p
+		}
+	}
+}
+
+// NativePedersenCommit is PedersenCommit out of the circuit, value and blinding are field elements (the witnesses of
+// the circuit) and are reduced modulo ℓ
+func NativePedersenCommit(value, blinding *big.Int) (twistededwards.PointAffine, error) {
+	if err := checkNativeElement(value); err != nil {
+		return twistededwards.PointAffine{}, fmt.Errorf("pedersen value: %w", err)
+	}
+	if err := checkNativeElement(blinding); err != nil {
+		return twistededwards.PointAffine{}, fmt.Errorf("pedersen blinding: %w", err)
+	}
+	return pedersenCommitNative(value, blinding), nil
+}
+
+func pedersenCommitNative(value, blinding *big.Int) twistededwards.PointAffine {
+	order := twistededwards.GetEdwardsCurve().Order
+	g, h := PedersenGenerators()
+	var c, hb twistededwards.PointAffine
+	c.ScalarMul(&g, new(big.Int).Mod(value, &order))
+	hb.ScalarMul(&h, new(big.Int).Mod(blinding, &order))
+	return *c.Add(&c, &hb)
+}
+
+// NativePedersenOpen returns whether c commits to value with blinding. Both are taken modulo ℓ, so the sum of the
+// values and the sum of the blindings open the sum of commitments (NativePedersenAdd).
+func NativePedersenOpen(c twistededwards.PointAffine, value, blinding *big.Int) bool {
+	if value == nil || blinding == nil {
+		return false
+	}
+	expected := pedersenCommitNative(value, blinding)
+	return expected.Equal(&c)
+}
+
+// NativePedersenAdd returns the sum of commitments, a commitment to the sum of their values with the sum of their
+// blindings
+func NativePedersenAdd(commitments ...twistededwards.PointAffine) twistededwards.PointAffine {
+	var sum twistededwards.PointAffine
+	sum.Y.SetOne()
+	for i := range commitments {
+		sum.Add(&sum, &commitments[i])
+	}
+	return sum
+}
+
+// NativePedersenBlinding returns a random blinding in [0, ℓ)
+func NativePedersenBlinding() (*big.Int, error) {
+	order := twistededwards.GetEdwardsCurve().Order
+	return rand.Int(rand.Reader, &order)
+}
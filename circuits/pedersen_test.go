@@ -0,0 +1,120 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	edwards "github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/test"
+	"math/big"
+	"testing"
+)
+
+type pedersenCircuit struct {
+	Value, Blinding frontend.Variable
+	Commitment      edwards.Point `gnark:",public"`
+}
+
+func (c *pedersenCircuit) Define(api frontend.API) error {
+	commitment := PedersenCommit(api, c.Value, c.Blinding)
+	api.AssertIsEqual(commitment.X, c.Commitment.X)
+	api.AssertIsEqual(commitment.Y, c.Commitment.Y)
+	return nil
+}
+
+func pedersenWitness(value, blinding *big.Int, c twistededwards.PointAffine) *pedersenCircuit {
+	return &pedersenCircuit{Value: value, Blinding: blinding, Commitment: edwards.Point{X: &c.X, Y: &c.Y}}
+}
+
+func TestPedersenGenerators(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	g, h := PedersenGenerators()
+	curve := twistededwards.GetEdwardsCurve()
+	assert.True(g.Equal(&curve.Base))
+	assert.True(h.IsOnCurve())
+	assert.False(h.IsZero())
+	assert.False(h.Equal(&g))
+	var p twistededwards.PointAffine
+	p.ScalarMul(&h, &curve.Order)
+	assert.True(p.IsZero(), "H should be in the subgroup of order ℓ")
+}
+
+func TestPedersenCommit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	order := twistededwards.GetEdwardsCurve().Order
+	rMinus1 := new(big.Int).Sub(fr.Modulus(), big.NewInt(1))
+	maxValue := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), PedersenValueBits), big.NewInt(1))
+	random, err := NativePedersenBlinding()
+	assert.NoError(err)
+	values := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(1000), maxValue}
+	// a blinding ≥ ℓ commits like blinding mod ℓ
+	blindings := []*big.Int{big.NewInt(0), random, new(big.Int).Sub(&order, big.NewInt(1)), rMinus1}
+
+	circuit := &pedersenCircuit{}
+	for _, value := range values {
+		for _, blinding := range blindings {
+			c, err := NativePedersenCommit(value, blinding)
+			assert.NoError(err)
+			assert.True(c.IsOnCurve())
+			assert.True(NativePedersenOpen(c, value, blinding))
+			assert.NoError(test.IsSolved(circuit, pedersenWitness(value, blinding, c), ecc.BN254, backend.GROTH16), "%v, %v", value, blinding)
+
+			// another value
+			other := new(big.Int).Xor(value, big.NewInt(1))
+			assert.False(NativePedersenOpen(c, other, blinding))
+			assert.Error(test.IsSolved(circuit, pedersenWitness(other, blinding, c), ecc.BN254, backend.GROTH16))
+		}
+	}
+
+	// the values are range checked
+	tooLarge := new(big.Int).Lsh(big.NewInt(1), PedersenValueBits)
+	c, err := NativePedersenCommit(tooLarge, random)
+	assert.NoError(err)
+	assert.Error(test.IsSolved(circuit, pedersenWitness(tooLarge, random, c), ecc.BN254, backend.GROTH16))
+
+	_, err = NativePedersenCommit(big.NewInt(-1), random)
+	assert.Error(err)
+	_, err = NativePedersenCommit(big.NewInt(1), fr.Modulus())
+	assert.Error(err)
+
+	// R1CS
+	cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, circuit)
+	assert.NoError(err)
+	t.Logf("pedersen commitment: %d constraints", cs.GetNbConstraints())
+	c, err = NativePedersenCommit(big.NewInt(42), random)
+	assert.NoError(err)
+	witness, err := frontend.NewWitness(pedersenWitness(big.NewInt(42), random, c), ecc.BN254)
+	assert.NoError(err)
+	assert.NoError(cs.IsSolved(witness))
+}
+
+func TestPedersenAdd(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	values := []int64{120, 75, 300, 1}
+	total, totalBlinding := new(big.Int), new(big.Int)
+	var commitments []twistededwards.PointAffine
+	for _, v := range values {
+		blinding, err := NativePedersenBlinding()
+		assert.NoError(err)
+		c, err := NativePedersenCommit(big.NewInt(v), blinding)
+		assert.NoError(err)
+		commitments = append(commitments, c)
+		total.Add(total, big.NewInt(v))
+		totalBlinding.Add(totalBlinding, blinding)
+	}
+
+	// the total opens without opening the bids
+	sum := NativePedersenAdd(commitments...)
+	assert.True(NativePedersenOpen(sum, total, totalBlinding))
+	assert.False(NativePedersenOpen(sum, new(big.Int).Add(total, big.NewInt(1)), totalBlinding))
+	assert.False(NativePedersenOpen(NativePedersenAdd(commitments[1:]...), total, totalBlinding))
+
+	empty := NativePedersenAdd()
+	assert.True(NativePedersenOpen(empty, big.NewInt(0), big.NewInt(0)))
+}
@@ -1,6 +1,7 @@
 package circuits
 
 import (
+	"fmt"
 	"github.com/consensys/gnark/frontend"
 	"math/big"
 )
@@ -55,17 +56,32 @@ func MixS(api frontend.API, in []frontend.Variable, s []*big.Int, r int) []front
 	return out
 }
 
-func PoseidonEx(api frontend.API, inputs []frontend.Variable, initialState frontend.Variable, nOuts int) []frontend.Variable {
+// Using recommended parameters from whitepaper https://eprint.iacr.org/2019/458.pdf (table 2, table 8)
+// Generated by https://extgit.iaik.tugraz.at/krypto/hadeshash/-/blob/master/code/calc_round_numbers.py
+// And rounded up to nearest integer that divides by t
+var poseidonRoundsP = [16]int{56, 57, 56, 60, 60, 63, 64, 63, 60, 66, 60, 65, 70, 60, 64, 68}
+
+// PoseidonRounds returns the number of full and partial rounds of Poseidon with 1 to 16 inputs
+func PoseidonRounds(nInputs int) (nRoundsF, nRoundsP int) {
+	return 8, poseidonRoundsP[nInputs-1]
+}
+
+func PoseidonEx(api frontend.API, inputs []frontend.Variable, initialState frontend.Variable, nOuts int) (out []frontend.Variable) {
+	if len(inputs) < 1 || len(inputs) > MaxPoseidonSpongeRate {
+		panic(fmt.Sprintf("poseidon: %d inputs, must be in [1, %d], hash more with PoseidonSponge", len(inputs), MaxPoseidonSpongeRate))
+	}
+	Gadget(api, GadgetPoseidon, func() {
+		out = poseidonEx(api, inputs, initialState, nOuts)
+	})
+	return out
+}
+
+func poseidonEx(api frontend.API, inputs []frontend.Variable, initialState frontend.Variable, nOuts int) []frontend.Variable {
 	nInputs := len(inputs)
 	out := make([]frontend.Variable, nOuts)
 
-	// Using recommended parameters from whitepaper https://eprint.iacr.org/2019/458.pdf (table 2, table 8)
-	// Generated by https://extgit.iaik.tugraz.at/krypto/hadeshash/-/blob/master/code/calc_round_numbers.py
-	// And rounded up to nearest integer that divides by t
-	nRoundsPC := [16]int{56, 57, 56, 60, 60, 63, 64, 63, 60, 66, 60, 65, 70, 60, 64, 68}
 	t := nInputs + 1
-	nRoundsF := 8
-	nRoundsP := nRoundsPC[t-2]
+	nRoundsF, nRoundsP := PoseidonRounds(nInputs)
 	c := POSEIDON_C(t)
 	s := POSEIDON_S(t)
 	m := POSEIDON_M(t)
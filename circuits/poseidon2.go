@@ -0,0 +1,203 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"math/big"
+	"sync"
+)
+
+// Poseidon2 (https://eprint.iacr.org/2023/323.pdf) for BN254 with the parameters of the reference implementation
+// https://github.com/HorizenLabs/poseidon2 (poseidon2_instance_bn256.rs): width 3, x⁵ S-box, 8 full rounds and 56
+// partial rounds, external matrix circ(2, 1, 1), internal matrix 1 + diag(1, 1, 2). The round constants are
+// generated like the reference, by the Grain LFSR of the Poseidon parameters script.
+
+// Poseidon2Width is the state size of the Poseidon2 permutation
+const Poseidon2Width = 3
+
+const (
+	poseidon2RoundsF = 8
+	poseidon2RoundsP = 56
+)
+
+var poseidon2Internal = [Poseidon2Width]int64{1, 1, 2}
+
+var poseidon2Constants struct {
+	once sync.Once
+	rc   [][Poseidon2Width]fr.Element
+}
+
+// poseidon2RoundConstants returns the constants of the rounds, only the first one is used in the partial rounds
+func poseidon2RoundConstants() [][Poseidon2Width]fr.Element {
+	poseidon2Constants.once.Do(func() {
+		// unlike Poseidon, a partial round only draws the constant of its first element
+		values := grainRoundConstants(Poseidon2Width, poseidon2RoundsF, poseidon2RoundsP, poseidon2RoundsF*Poseidon2Width+poseidon2RoundsP)
+		rc := make([][Poseidon2Width]fr.Element, poseidon2RoundsF+poseidon2RoundsP)
+		for r := range rc {
+			width := Poseidon2Width
+			if r >= poseidon2RoundsF/2 && r < poseidon2RoundsF/2+poseidon2RoundsP {
+				width = 1
+			}
+			for j := 0; j < width; j++ {
+				rc[r][j].SetBigInt(values[0])
+				values = values[1:]
+			}
+		}
+		poseidon2Constants.rc = rc
+	})
+	return poseidon2Constants.rc
+}
+
+// Poseidon2Permutation returns the Poseidon2 permutation of the state
+func Poseidon2Permutation(api frontend.API, state [Poseidon2Width]frontend.Variable) (out [Poseidon2Width]frontend.Variable) {
+	Gadget(api, GadgetPoseidon, func() {
+		out = poseidon2Permutation(api, state)
+	})
+	return out
+}
+
+func poseidon2Permutation(api frontend.API, state [Poseidon2Width]frontend.Variable) [Poseidon2Width]frontend.Variable {
+	rc := poseidon2RoundConstants()
+	external := func() {
+		sum := api.Add(state[0], state[1], state[2])
+		for j := range state {
+			state[j] = api.Add(state[j], sum)
+		}
+	}
+	fullRound := func(r int) {
+		for j := range state {
+			state[j] = Sigma(api, api.Add(state[j], rc[r][j]))
+		}
+		external()
+	}
+
+	external()
+	for r := 0; r < poseidon2RoundsF/2; r++ {
+		fullRound(r)
+	}
+	for r := poseidon2RoundsF / 2; r < poseidon2RoundsF/2+poseidon2RoundsP; r++ {
+		state[0] = Sigma(api, api.Add(state[0], rc[r][0]))
+		sum := api.Add(state[0], state[1], state[2])
+		for j := range state {
+			state[j] = api.Add(api.Mul(state[j], poseidon2Internal[j]), sum)
+		}
+	}
+	for r := poseidon2RoundsF/2 + poseidon2RoundsP; r < poseidon2RoundsF+poseidon2RoundsP; r++ {
+		fullRound(r)
+	}
+	return state
+}
+
+// NativePoseidon2Permutation is Poseidon2Permutation out of the circuit, the elements of state must be reduced
+func NativePoseidon2Permutation(state [Poseidon2Width]*big.Int) ([Poseidon2Width]*big.Int, error) {
+	var s [Poseidon2Width]fr.Element
+	for j, v := range state {
+		if err := checkNativeElement(v); err != nil {
+			return [Poseidon2Width]*big.Int{}, err
+		}
+		s[j].SetBigInt(v)
+	}
+	s = poseidon2PermutationNative(s)
+	var out [Poseidon2Width]*big.Int
+	for j := range s {
+		out[j] = s[j].ToBigIntRegular(new(big.Int))
+	}
+	return out, nil
+}
+
+func poseidon2PermutationNative(state [Poseidon2Width]fr.Element) [Poseidon2Width]fr.Element {
+	rc := poseidon2RoundConstants()
+	var diag [Poseidon2Width]fr.Element
+	for j, d := range poseidon2Internal {
+		diag[j].SetInt64(d)
+	}
+	external := func() {
+		var sum fr.Element
+		sum.Add(&state[0], &state[1]).Add(&sum, &state[2])
+		for j := range state {
+			state[j].Add(&state[j], &sum)
+		}
+	}
+	fullRound := func(r int) {
+		for j := range state {
+			state[j].Add(&state[j], &rc[r][j])
+			sigmaNative(&state[j])
+		}
+		external()
+	}
+
+	external()
+	for r := 0; r < poseidon2RoundsF/2; r++ {
+		fullRound(r)
+	}
+	for r := poseidon2RoundsF / 2; r < poseidon2RoundsF/2+poseidon2RoundsP; r++ {
+		state[0].Add(&state[0], &rc[r][0])
+		sigmaNative(&state[0])
+		var sum fr.Element
+		sum.Add(&state[0], &state[1]).Add(&sum, &state[2])
+		for j := range state {
+			state[j].Mul(&state[j], &diag[j]).Add(&state[j], &sum)
+		}
+	}
+	for r := poseidon2RoundsF/2 + poseidon2RoundsP; r < poseidon2RoundsF+poseidon2RoundsP; r++ {
+		fullRound(r)
+	}
+	return state
+}
+
+// grainRoundConstants returns the first count round constants of the Grain LFSR of the reference script
+// generate_parameters_grain.sage for BN254, the x⁵ S-box and the given width and rounds
+func grainRoundConstants(t, roundsF, roundsP, count int) []*big.Int {
+	const n = fr.Bits
+	// field (2 bits, 1 for a prime field), S-box (4 bits, 0 for xᵅ), n, t, roundsF, roundsP, then 30 ones
+	var state [80]byte
+	pos := 0
+	push := func(v, bits int) {
+		for i := bits - 1; i >= 0; i-- {
+			state[pos] = byte(v>>i) & 1
+			pos++
+		}
+	}
+	push(1, 2)
+	push(0, 4)
+	push(n, 12)
+	push(t, 12)
+	push(roundsF, 10)
+	push(roundsP, 10)
+	push(1<<30-1, 30)
+
+	next := func() byte {
+		bit := state[62] ^ state[51] ^ state[38] ^ state[23] ^ state[13] ^ state[0]
+		copy(state[:], state[1:])
+		state[79] = bit
+		return bit
+	}
+	for i := 0; i < 160; i++ {
+		next()
+	}
+	// the output bits are the second bits of the pairs starting with 1
+	random := func() byte {
+		for next() == 0 {
+			next()
+		}
+		return next()
+	}
+
+	modulus := fr.Modulus()
+	constants := make([]*big.Int, count)
+	for i := range constants {
+		c := new(big.Int)
+		for {
+			c.SetUint64(0)
+			for b := 0; b < n; b++ {
+				c.Lsh(c, 1)
+				c.SetBit(c, 0, uint(random()))
+			}
+			if c.Cmp(modulus) < 0 {
+				break
+			}
+		}
+		constants[i] = c
+	}
+	return constants
+}
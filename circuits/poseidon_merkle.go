@@ -0,0 +1,101 @@
+package circuits
+
+import (
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	stdHash "hash"
+	"math/big"
+)
+
+// PoseidonMaxData is the largest data hashed by NewPoseidonHash, 16 field elements
+const PoseidonMaxData = MaxPoseidonSpongeRate * fr.Bytes
+
+type poseidonDigest struct {
+	data []byte
+}
+
+// NewPoseidonHash returns Poseidon as a native hash: the data is split in 32 bytes big endian field elements (the
+// last one may be shorter, the empty data is one zero element), reduced modulo r like MiMC, and Sum appends their
+// NativePoseidon. Write fails beyond PoseidonMaxData bytes. The in-circuit counterpart is PoseidonHasher.
+//
+// The trees hash leaves of one element (merkle.MaxLeafData): a leaf of the 64 bytes left ‖ right would hash like the
+// node of left and right.
+func NewPoseidonHash() stdHash.Hash {
+	return &poseidonDigest{}
+}
+
+func (d *poseidonDigest) Write(p []byte) (int, error) {
+	if len(d.data)+len(p) > PoseidonMaxData {
+		return 0, fmt.Errorf("poseidon: %d bytes, hashes at most %d", len(d.data)+len(p), PoseidonMaxData)
+	}
+	d.data = append(d.data, p...)
+	return len(p), nil
+}
+
+func (d *poseidonDigest) Sum(b []byte) []byte {
+	var elements []*big.Int
+	for _, e := range hashElements(d.data) {
+		elements = append(elements, e.ToBigIntRegular(new(big.Int)))
+	}
+	h, err := NativePoseidon(elements)
+	if err != nil {
+		// the elements are reduced and at most 16
+		panic(err)
+	}
+	var sum [fr.Bytes]byte
+	return append(b, h.FillBytes(sum[:])...)
+}
+
+func (d *poseidonDigest) Reset() {
+	d.data = nil
+}
+
+func (d *poseidonDigest) Size() int {
+	return fr.Bytes
+}
+
+func (d *poseidonDigest) BlockSize() int {
+	return fr.Bytes
+}
+
+// PoseidonHasher is Poseidon as a gnark hash.Hash: Sum is the Poseidon of the 1 to 16 variables written since the
+// last Reset, or of a single zero when nothing was written. Its native counterpart is NewPoseidonHash.
+type PoseidonHasher struct {
+	api  frontend.API
+	data []frontend.Variable
+}
+
+var _ hash.Hash = (*PoseidonHasher)(nil)
+
+// NewPoseidonHasher returns an empty Poseidon hasher
+func NewPoseidonHasher(api frontend.API) *PoseidonHasher {
+	return &PoseidonHasher{api: api}
+}
+
+// Write adds data to hash
+func (h *PoseidonHasher) Write(data ...frontend.Variable) {
+	h.data = append(h.data, data...)
+}
+
+// Sum returns the Poseidon of the data and empties it
+func (h *PoseidonHasher) Sum() frontend.Variable {
+	data := h.data
+	if len(data) == 0 {
+		data = []frontend.Variable{0}
+	}
+	sum := Poseidon(h.api, data)
+	h.Reset()
+	return sum
+}
+
+// Reset empties the data
+func (h *PoseidonHasher) Reset() {
+	h.data = nil
+}
+
+// VerifyPoseidonMerkleProof is VerifyMerkleProof with Poseidon, for the trees of merkle.NewMerkleTreeBytesPoseidon
+func VerifyPoseidonMerkleProof(api frontend.API, merkleRoot frontend.Variable, proofSet, helper []frontend.Variable) {
+	VerifyMerkleProof(api, NewPoseidonHasher(api), merkleRoot, proofSet, helper)
+}
@@ -0,0 +1,188 @@
+package circuits
+
+import (
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"math/big"
+	"sync"
+)
+
+// poseidonParams are the constants of poseidon_constants.go for a width t, parsed once for the native implementation
+type poseidonParams struct {
+	c, s []fr.Element
+	m, p [][]fr.Element
+}
+
+var poseidonParamsCache [18]struct {
+	once   sync.Once
+	params *poseidonParams
+}
+
+func nativePoseidonParams(t int) *poseidonParams {
+	cache := &poseidonParamsCache[t]
+	cache.once.Do(func() {
+		cache.params = &poseidonParams{
+			c: toElements(POSEIDON_C(t)),
+			s: toElements(POSEIDON_S(t)),
+			m: toMatrix(POSEIDON_M(t)),
+			p: toMatrix(POSEIDON_P(t)),
+		}
+	})
+	return cache.params
+}
+
+func toElements(v []*big.Int) []fr.Element {
+	e := make([]fr.Element, len(v))
+	for i := range v {
+		e[i].SetBigInt(v[i])
+	}
+	return e
+}
+
+func toMatrix(m [][]*big.Int) [][]fr.Element {
+	e := make([][]fr.Element, len(m))
+	for i := range m {
+		e[i] = toElements(m[i])
+	}
+	return e
+}
+
+func sigmaNative(x *fr.Element) {
+	var x2, x4 fr.Element
+	x2.Square(x)
+	x4.Square(&x2)
+	x.Mul(&x4, x)
+}
+
+func mixNative(state []fr.Element, m [][]fr.Element) []fr.Element {
+	t := len(state)
+	out := make([]fr.Element, t)
+	var mul fr.Element
+	for i := 0; i < t; i++ {
+		for j := 0; j < t; j++ {
+			mul.Mul(&m[j][i], &state[j])
+			out[i].Add(&out[i], &mul)
+		}
+	}
+	return out
+}
+
+// NativePoseidon is Poseidon out of the circuit, built from the same constants: it hashes 1 to 16 reduced inputs
+// like github.com/iden3/go-iden3-crypto/poseidon.Hash
+func NativePoseidon(inputs []*big.Int) (*big.Int, error) {
+	out, err := NativePoseidonEx(inputs, big.NewInt(0), 1)
+	if err != nil {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+// NativePoseidonEx is PoseidonEx out of the circuit: the first nOuts elements of the permutation of the state
+// initialState, inputs...
+func NativePoseidonEx(inputs []*big.Int, initialState *big.Int, nOuts int) ([]*big.Int, error) {
+	if len(inputs) < 1 || len(inputs) > MaxPoseidonSpongeRate {
+		return nil, fmt.Errorf("poseidon: %d inputs, must be in [1, %d]", len(inputs), MaxPoseidonSpongeRate)
+	}
+	if nOuts < 1 || nOuts > len(inputs)+1 {
+		return nil, fmt.Errorf("poseidon: %d outputs, must be in [1, %d]", nOuts, len(inputs)+1)
+	}
+	state := make([]fr.Element, len(inputs)+1)
+	for i, v := range append([]*big.Int{initialState}, inputs...) {
+		if err := checkNativeElement(v); err != nil {
+			return nil, fmt.Errorf("poseidon: %w", err)
+		}
+		state[i].SetBigInt(v)
+	}
+	out := make([]*big.Int, nOuts)
+	for i, e := range poseidonExNative(state[1:], state[0], nOuts) {
+		out[i] = e.ToBigIntRegular(new(big.Int))
+	}
+	return out, nil
+}
+
+// poseidonExNative is poseidonEx out of the circuit, step by step: inputs has 1 to 16 elements
+func poseidonExNative(inputs []fr.Element, initialState fr.Element, nOuts int) []fr.Element {
+	t := len(inputs) + 1
+	nRoundsF, nRoundsP := PoseidonRounds(len(inputs))
+	params := nativePoseidonParams(t)
+	c, s := params.c, params.s
+
+	state := make([]fr.Element, t)
+	state[0] = initialState
+	copy(state[1:], inputs)
+	ark := func(r int) {
+		for j := range state {
+			state[j].Add(&state[j], &c[j+r])
+		}
+	}
+	ark(0)
+
+	for r := 0; r < nRoundsF/2-1; r++ {
+		for j := range state {
+			sigmaNative(&state[j])
+		}
+		ark((r + 1) * t)
+		state = mixNative(state, params.m)
+	}
+
+	for j := range state {
+		sigmaNative(&state[j])
+	}
+	ark(nRoundsF / 2 * t)
+	state = mixNative(state, params.p)
+
+	var mul fr.Element
+	for r := 0; r < nRoundsP; r++ {
+		sigmaNative(&state[0])
+		state[0].Add(&state[0], &c[(nRoundsF/2+1)*t+r])
+		var newState0 fr.Element
+		for j := range state {
+			mul.Mul(&s[(t*2-1)*r+j], &state[j])
+			newState0.Add(&newState0, &mul)
+		}
+		for k := 1; k < t; k++ {
+			mul.Mul(&state[0], &s[(t*2-1)*r+t+k-1])
+			state[k].Add(&state[k], &mul)
+		}
+		state[0] = newState0
+	}
+
+	for r := 0; r < nRoundsF/2-1; r++ {
+		for j := range state {
+			sigmaNative(&state[j])
+		}
+		ark((nRoundsF/2+1)*t + nRoundsP + r*t)
+		state = mixNative(state, params.m)
+	}
+
+	for j := range state {
+		sigmaNative(&state[j])
+	}
+	// the last Mix, only the outputs
+	out := make([]fr.Element, nOuts)
+	for i := range out {
+		for j := range state {
+			mul.Mul(&params.m[j][i], &state[j])
+			out[i].Add(&out[i], &mul)
+		}
+	}
+	return out
+}
+
+// PoseidonReferenceConstants returns the constants of the reference Poseidon with 1 to 16 inputs, for the
+// implementations (e.g. the EVM one) of the unoptimized permutation: each round adds the t constants c[r*t:(r+1)*t]
+// to the state, applies the S-box to the whole state in the full rounds and to state[0] in the partial rounds, then
+// mixes the state with state[i] = Σⱼ m[j][i] * state[j]. The full rounds are the first and last nRoundsF/2.
+func PoseidonReferenceConstants(nInputs int) (c []*big.Int, m [][]*big.Int) {
+	t := nInputs + 1
+	nRoundsF, nRoundsP := PoseidonRounds(nInputs)
+	// the optimized constants of poseidon_constants.go are derived from these ones, with the same matrix
+	return grainRoundConstants(t, nRoundsF, nRoundsP, (nRoundsF+nRoundsP)*t), POSEIDON_M(t)
+}
+
+func checkNativeElement(v *big.Int) error {
+	if v == nil || v.Sign() < 0 || v.Cmp(fr.Modulus()) >= 0 {
+		return fmt.Errorf("%v is not a reduced field element", v)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package circuits
+
+import (
+	"crypto/rand"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"math/big"
+	"testing"
+)
+
+type poseidonExCircuit struct {
+	In           []frontend.Variable
+	InitialState frontend.Variable
+	Out          []frontend.Variable
+}
+
+func (c *poseidonExCircuit) Define(api frontend.API) error {
+	out := PoseidonEx(api, c.In, c.InitialState, len(c.Out))
+	for i := range out {
+		api.AssertIsEqual(out[i], c.Out[i])
+	}
+	return nil
+}
+
+func randomElements(t *testing.T, n int) []*big.Int {
+	elements := make([]*big.Int, n)
+	for i := range elements {
+		r, err := rand.Int(rand.Reader, fr.Modulus())
+		if err != nil {
+			t.Fatal(err)
+		}
+		elements[i] = r
+	}
+	return elements
+}
+
+func TestNativePoseidon(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for n := 1; n <= MaxPoseidonSpongeRate; n++ {
+		inputs := randomElements(t, n)
+
+		expected, err := poseidon.Hash(inputs)
+		assert.NoError(err)
+		h, err := NativePoseidon(inputs)
+		assert.NoError(err)
+		assert.Equal(expected, h, "%d inputs", n)
+
+		// every output of the permutation, from a non zero initial state
+		initialState := randomElements(t, 1)[0]
+		out, err := NativePoseidonEx(inputs, initialState, n+1)
+		assert.NoError(err)
+		h0, err := NativePoseidonEx(inputs, big.NewInt(0), 1)
+		assert.NoError(err)
+		assert.Equal(expected, h0[0])
+
+		circuit := &poseidonExCircuit{In: make([]frontend.Variable, n), Out: make([]frontend.Variable, n+1)}
+		witness := &poseidonExCircuit{In: make([]frontend.Variable, n), InitialState: initialState, Out: make([]frontend.Variable, n+1)}
+		for i := range inputs {
+			witness.In[i] = inputs[i]
+		}
+		for i := range out {
+			witness.Out[i] = out[i]
+		}
+		assert.NoError(test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16), "%d inputs", n)
+
+		witness.Out[n] = new(big.Int).Add(out[n], big.NewInt(1))
+		assert.Error(test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16))
+	}
+
+	_, err := NativePoseidon(nil)
+	assert.Error(err)
+	_, err = NativePoseidon(make([]*big.Int, MaxPoseidonSpongeRate+1))
+	assert.Error(err)
+	_, err = NativePoseidon([]*big.Int{fr.Modulus()})
+	assert.Error(err)
+	_, err = NativePoseidonEx([]*big.Int{big.NewInt(1)}, big.NewInt(0), 3)
+	assert.Error(err)
+}
@@ -0,0 +1,162 @@
+package circuits
+
+import (
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"math/big"
+)
+
+// The sponges hash inputs of any length (https://eprint.iacr.org/2019/458.pdf, section 4.2): the first element of
+// the state is the capacity, set to 2⁶⁴ + nOuts - 1, the others are the rate. The inputs are padded with a 1 then
+// zeros to a multiple of the rate, added to the rate and permuted chunk by chunk, then the outputs are read from the
+// rate, permuting again between the chunks of outputs.
+//
+// The sponges are a construction of this project, no other implementation computes them: only their permutations
+// are checked against reference vectors (circomlib for PoseidonEx, HorizenLabs/poseidon2 for Poseidon2Permutation).
+// TestPoseidonSpongeVectors pins their outputs, a change of the padding, the capacity or the chunking breaks it.
+
+// MaxPoseidonSpongeRate is the largest rate of PoseidonSponge, the width of the largest Poseidon of PoseidonEx minus
+// the capacity
+const MaxPoseidonSpongeRate = 16
+
+// Poseidon2SpongeRate is the rate of Poseidon2Sponge
+const Poseidon2SpongeRate = Poseidon2Width - 1
+
+// PoseidonSponge returns nOuts elements of the Poseidon sponge of the inputs, with rate + 1 wide permutations
+func PoseidonSponge(api frontend.API, rate int, inputs []frontend.Variable, nOuts int) (out []frontend.Variable) {
+	checkSponge(rate, nOuts)
+	Gadget(api, GadgetPoseidon, func() {
+		out = sponge(api, rate, inputs, nOuts, func(state []frontend.Variable) []frontend.Variable {
+			return PoseidonEx(api, state[1:], state[0], len(state))
+		})
+	})
+	return out
+}
+
+// Poseidon2Sponge returns nOuts elements of the Poseidon2 sponge of the inputs, two inputs per permutation
+func Poseidon2Sponge(api frontend.API, inputs []frontend.Variable, nOuts int) (out []frontend.Variable) {
+	checkSponge(Poseidon2SpongeRate, nOuts)
+	Gadget(api, GadgetPoseidon, func() {
+		out = sponge(api, Poseidon2SpongeRate, inputs, nOuts, func(state []frontend.Variable) []frontend.Variable {
+			var s [Poseidon2Width]frontend.Variable
+			copy(s[:], state)
+			s = Poseidon2Permutation(api, s)
+			return s[:]
+		})
+	})
+	return out
+}
+
+func checkSponge(rate, nOuts int) {
+	if err := checkSpongeParams(rate, nOuts); err != nil {
+		panic(err.Error())
+	}
+}
+
+func checkSpongeParams(rate, nOuts int) error {
+	if rate < 1 || rate > MaxPoseidonSpongeRate {
+		return fmt.Errorf("sponge: rate %d, must be in [1, %d]", rate, MaxPoseidonSpongeRate)
+	}
+	if nOuts < 1 {
+		return fmt.Errorf("sponge: %d outputs", nOuts)
+	}
+	return nil
+}
+
+// spongeCapacity is the initial capacity of a sponge with nOuts outputs
+func spongeCapacity(nOuts int) *big.Int {
+	c := new(big.Int).Lsh(big.NewInt(1), 64)
+	return c.Add(c, big.NewInt(int64(nOuts-1)))
+}
+
+// spongePadding returns the number of zeros after the padding 1 of n inputs
+func spongePadding(n, rate int) int {
+	return (rate - (n+1)%rate) % rate
+}
+
+func sponge(api frontend.API, rate int, inputs []frontend.Variable, nOuts int, permute func([]frontend.Variable) []frontend.Variable) []frontend.Variable {
+	padded := make([]frontend.Variable, 0, len(inputs)+rate)
+	padded = append(padded, inputs...)
+	padded = append(padded, 1)
+	for i := spongePadding(len(inputs), rate); i > 0; i-- {
+		padded = append(padded, 0)
+	}
+
+	state := make([]frontend.Variable, rate+1)
+	state[0] = spongeCapacity(nOuts)
+	for i := 1; i <= rate; i++ {
+		state[i] = 0
+	}
+	for ; len(padded) > 0; padded = padded[rate:] {
+		for i := 0; i < rate; i++ {
+			state[i+1] = api.Add(state[i+1], padded[i])
+		}
+		state = permute(state)
+	}
+
+	out := make([]frontend.Variable, 0, nOuts)
+	for {
+		for i := 1; i <= rate && len(out) < nOuts; i++ {
+			out = append(out, state[i])
+		}
+		if len(out) == nOuts {
+			return out
+		}
+		state = permute(state)
+	}
+}
+
+// NativePoseidonSponge is PoseidonSponge out of the circuit, the inputs must be reduced
+func NativePoseidonSponge(rate int, inputs []*big.Int, nOuts int) ([]*big.Int, error) {
+	if err := checkSpongeParams(rate, nOuts); err != nil {
+		return nil, err
+	}
+	return spongeNative(rate, inputs, nOuts, func(state []fr.Element) []fr.Element {
+		return poseidonExNative(state[1:], state[0], len(state))
+	})
+}
+
+// NativePoseidon2Sponge is Poseidon2Sponge out of the circuit, the inputs must be reduced
+func NativePoseidon2Sponge(inputs []*big.Int, nOuts int) ([]*big.Int, error) {
+	if err := checkSpongeParams(Poseidon2SpongeRate, nOuts); err != nil {
+		return nil, err
+	}
+	return spongeNative(Poseidon2SpongeRate, inputs, nOuts, func(state []fr.Element) []fr.Element {
+		var s [Poseidon2Width]fr.Element
+		copy(s[:], state)
+		s = poseidon2PermutationNative(s)
+		return s[:]
+	})
+}
+
+func spongeNative(rate int, inputs []*big.Int, nOuts int, permute func([]fr.Element) []fr.Element) ([]*big.Int, error) {
+	padded := make([]fr.Element, len(inputs)+1+spongePadding(len(inputs), rate))
+	for i, v := range inputs {
+		if err := checkNativeElement(v); err != nil {
+			return nil, err
+		}
+		padded[i].SetBigInt(v)
+	}
+	padded[len(inputs)].SetOne()
+
+	state := make([]fr.Element, rate+1)
+	state[0].SetBigInt(spongeCapacity(nOuts))
+	for ; len(padded) > 0; padded = padded[rate:] {
+		for i := 0; i < rate; i++ {
+			state[i+1].Add(&state[i+1], &padded[i])
+		}
+		state = permute(state)
+	}
+
+	out := make([]*big.Int, 0, nOuts)
+	for {
+		for i := 1; i <= rate && len(out) < nOuts; i++ {
+			out = append(out, state[i].ToBigIntRegular(new(big.Int)))
+		}
+		if len(out) == nOuts {
+			return out, nil
+		}
+		state = permute(state)
+	}
+}
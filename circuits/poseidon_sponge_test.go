@@ -0,0 +1,217 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"math/big"
+	"testing"
+)
+
+type spongeCircuit struct {
+	In  []frontend.Variable
+	Out []frontend.Variable
+	// Poseidon2Sponge when rate is 0
+	rate int
+}
+
+func (c *spongeCircuit) Define(api frontend.API) error {
+	var out []frontend.Variable
+	if c.rate == 0 {
+		out = Poseidon2Sponge(api, c.In, len(c.Out))
+	} else {
+		out = PoseidonSponge(api, c.rate, c.In, len(c.Out))
+	}
+	for i := range out {
+		api.AssertIsEqual(out[i], c.Out[i])
+	}
+	return nil
+}
+
+type poseidon2Circuit struct {
+	In, Out [Poseidon2Width]frontend.Variable
+}
+
+func (c *poseidon2Circuit) Define(api frontend.API) error {
+	out := Poseidon2Permutation(api, c.In)
+	for i := range out {
+		api.AssertIsEqual(out[i], c.Out[i])
+	}
+	return nil
+}
+
+func spongeInputs(n int) []*big.Int {
+	inputs := make([]*big.Int, n)
+	for i := range inputs {
+		inputs[i] = big.NewInt(int64(i + 1))
+	}
+	return inputs
+}
+
+func spongeWitness(inputs, outputs []*big.Int) (*spongeCircuit, *spongeCircuit) {
+	circuit := &spongeCircuit{In: make([]frontend.Variable, len(inputs)), Out: make([]frontend.Variable, len(outputs))}
+	witness := &spongeCircuit{In: make([]frontend.Variable, len(inputs)), Out: make([]frontend.Variable, len(outputs))}
+	for i, v := range inputs {
+		witness.In[i] = v
+	}
+	for i, v := range outputs {
+		witness.Out[i] = v
+	}
+	return circuit, witness
+}
+
+func TestPoseidon2Vector(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// test vector of the reference implementation https://github.com/HorizenLabs/poseidon2
+	hex := func(s string) *big.Int {
+		v, _ := new(big.Int).SetString(s, 16)
+		return v
+	}
+	in := [Poseidon2Width]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2)}
+	expected := [Poseidon2Width]*big.Int{
+		hex("0bb61d24daca55eebcb1929a82650f328134334da98ea4f847f760054f4a3033"),
+		hex("303b6f7c86d043bfcbcc80214f26a30277a15d3f74ca654992defe7ff8d03570"),
+		hex("1ed25194542b12eef8617361c3ba7c52e660b145994427cc86296242cf766ec8"),
+	}
+	out, err := NativePoseidon2Permutation(in)
+	assert.NoError(err)
+	assert.Equal(expected, out)
+
+	var witness poseidon2Circuit
+	for i := range in {
+		witness.In[i], witness.Out[i] = in[i], expected[i]
+	}
+	assert.NoError(test.IsSolved(&poseidon2Circuit{}, &witness, ecc.BN254, backend.GROTH16))
+
+	_, err = NativePoseidon2Permutation([Poseidon2Width]*big.Int{big.NewInt(0), big.NewInt(1), fr.Modulus()})
+	assert.Error(err)
+}
+
+func TestPoseidonSponge(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, rate := range []int{0, 1, 2, 4, MaxPoseidonSpongeRate} {
+		for _, n := range []int{0, 1, 3, 4, 5, 9, 17} {
+			for _, nOuts := range []int{1, 3, 5} {
+				inputs := spongeInputs(n)
+				var outputs []*big.Int
+				var err error
+				if rate == 0 {
+					outputs, err = NativePoseidon2Sponge(inputs, nOuts)
+				} else {
+					outputs, err = NativePoseidonSponge(rate, inputs, nOuts)
+				}
+				assert.NoError(err)
+				assert.Len(outputs, nOuts)
+
+				circuit, witness := spongeWitness(inputs, outputs)
+				circuit.rate = rate
+				assert.NoError(test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16), "rate %d, %d inputs, %d outputs", rate, n, nOuts)
+
+				witness.Out[nOuts-1] = new(big.Int).Add(outputs[nOuts-1], big.NewInt(1))
+				assert.Error(test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16))
+			}
+		}
+	}
+
+	// a single chunk is a single permutation of the padded inputs
+	capacity := spongeCapacity(1)
+	out, err := NativePoseidonSponge(2, []*big.Int{big.NewInt(7)}, 1)
+	assert.NoError(err)
+	var in [2]fr.Element
+	in[0].SetInt64(7)
+	in[1].SetOne()
+	var c fr.Element
+	c.SetBigInt(capacity)
+	expected := poseidonExNative(in[:], c, 3)[1]
+	assert.Equal(expected.ToBigIntRegular(new(big.Int)), out[0])
+
+	// the padding separates the inputs ending with zeros or a one
+	seen := make(map[string]bool)
+	for _, inputs := range [][]*big.Int{{big.NewInt(7)}, {big.NewInt(7), big.NewInt(0)}, {big.NewInt(7), big.NewInt(1)}, {}, {big.NewInt(0)}} {
+		out, err := NativePoseidonSponge(2, inputs, 1)
+		assert.NoError(err)
+		assert.False(seen[out[0].String()], "collision for %v", inputs)
+		seen[out[0].String()] = true
+	}
+
+	// with the capacity 0, a chunk of rate - 1 inputs hashes like Poseidon
+	h, err := poseidon.Hash([]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(1)})
+	assert.NoError(err)
+	in3 := toElements([]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(1)})
+	assert.Equal(h, poseidonExNative(in3, fr.Element{}, 1)[0].ToBigIntRegular(new(big.Int)))
+
+	_, err = NativePoseidonSponge(MaxPoseidonSpongeRate+1, nil, 1)
+	assert.Error(err)
+	_, err = NativePoseidonSponge(2, []*big.Int{fr.Modulus()}, 1)
+	assert.Error(err)
+}
+
+func TestPoseidonSpongeVectors(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// outputs of this implementation, the sponges have no external reference (see poseidon_sponge.go)
+	hex := func(s string) *big.Int {
+		v, _ := new(big.Int).SetString(s, 16)
+		return v
+	}
+	for _, v := range []struct {
+		rate     int // Poseidon2Sponge when 0
+		n        int // inputs 1, 2, ..., n
+		expected []*big.Int
+	}{
+		{2, 0, []*big.Int{hex("14b2e5484b232721d64f405caa487febbce835dd07c5de940f2a775dc9aa0da6")}},
+		{2, 5, []*big.Int{hex("215127467a74f65f01c6bb626af3baf8f15d84662bb410ea779dfc0a656280c8")}},
+		{2, 5, []*big.Int{
+			hex("14b3af23b756a4e7ba11a10b6c7e1fab28848de65b6833e3e4f088569c889bc0"),
+			hex("0d39c183c1d7af8d8af4c74395dfbe51e5c3f08f50f06dbb6b66263a1663b11a"),
+			hex("1a4ceff73e86d9385afb121feaefb17bd8deaee39ee136d66656105ecebcda04"),
+		}},
+		{4, 5, []*big.Int{
+			hex("2730df5eab1e603e2df404f590a0af46e66e3322545355ae92d5d4cfce372b74"),
+			hex("1a74d5b7e249a8b7463fecea8d4ff72dea196088d2746ac9c49557b94c83ffa6"),
+		}},
+		{MaxPoseidonSpongeRate, 5, []*big.Int{hex("1ce6af11b68762309c9200292ce077770a75f61489556a1321399a6127e6c8e6")}},
+		{0, 0, []*big.Int{hex("2a71d0f91bf274cd0fdf02f8cd49faff41cd5735a9b1c27895b22c9043ee28c1")}},
+		{0, 5, []*big.Int{hex("16f60edd1dec88121ca28b7a4165631f748222eab2f7b36e81c9c3d86bdb72fc")}},
+		{0, 5, []*big.Int{
+			hex("1741a14c5f7a44fe574254228b4265b617759842ea9ce223b4ca694f975f4f12"),
+			hex("0ddcb2ab9549532cf3b6e522b78990c02dc32f3eb3023fdac3a02307d00ae5d3"),
+			hex("099a172c39ca955487a3dc213a5e5501e699779f67ff45adfb22aab09d4d0033"),
+		}},
+	} {
+		inputs := spongeInputs(v.n)
+		var outputs []*big.Int
+		var err error
+		if v.rate == 0 {
+			outputs, err = NativePoseidon2Sponge(inputs, len(v.expected))
+		} else {
+			outputs, err = NativePoseidonSponge(v.rate, inputs, len(v.expected))
+		}
+		assert.NoError(err)
+		assert.Equal(v.expected, outputs, "rate %d, %d inputs", v.rate, v.n)
+
+		circuit, witness := spongeWitness(inputs, v.expected)
+		circuit.rate = v.rate
+		assert.NoError(test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16), "rate %d, %d inputs", v.rate, v.n)
+	}
+}
+
+func TestPoseidonSpongeConstraints(t *testing.T) {
+	for _, rate := range []int{0, 2, 4} {
+		for _, n := range []int{2, 16, 64} {
+			circuit, _ := spongeWitness(spongeInputs(n), make([]*big.Int, 1))
+			circuit.rate = rate
+			ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, circuit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Logf("rate %d (0 is Poseidon2), %d inputs: %d constraints", rate, n, ccs.GetNbConstraints())
+		}
+	}
+}
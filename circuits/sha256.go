@@ -0,0 +1,177 @@
+package circuits
+
+import "github.com/consensys/gnark/frontend"
+
+// SHA-256 (FIPS 180-4) of a statically sized message of bytes. The 32-bit words are arrays of bits, least
+// significant first: the rotations and shifts are free, the boolean functions cost 1 or 2 constraints per bit and
+// the additions modulo 2³² are a single decomposition of the sum. A block of 64 bytes is about 30k constraints.
+
+// Sha256Size is the size in bytes of a SHA-256 digest
+const Sha256Size = 32
+
+const sha256BlockSize = 64
+
+var sha256K = [64]uint32{
+	0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+	0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+	0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+	0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+	0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+	0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+	0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+	0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
+}
+
+var sha256IV = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+type sha256Word [32]frontend.Variable
+
+// Sha256 returns the 32 bytes of the SHA-256 of the bytes mBytes, which are range checked. A double SHA-256 (as in
+// Bitcoin) is Sha256(api, Sha256(api, m)).
+func Sha256(api frontend.API, mBytes []frontend.Variable) (out []frontend.Variable) {
+	Gadget(api, GadgetSha256, func() {
+		out = sha256Sum(api, mBytes)
+	})
+	return out
+}
+
+func sha256Sum(api frontend.API, mBytes []frontend.Variable) []frontend.Variable {
+	// the padding 0x80, 0...0, then the length in bits on 8 bytes, is known at compile time
+	length := len(mBytes)
+	padded := make([]frontend.Variable, 0, (length+9+sha256BlockSize-1)/sha256BlockSize*sha256BlockSize)
+	padded = append(padded, mBytes...)
+	padded = append(padded, 0x80)
+	for (len(padded)+8)%sha256BlockSize != 0 {
+		padded = append(padded, 0)
+	}
+	bitLength := uint64(length) * 8
+	for i := 7; i >= 0; i-- {
+		padded = append(padded, byte(bitLength>>(8*i)))
+	}
+
+	var h [8]sha256Word
+	for i, v := range sha256IV {
+		h[i] = sha256Constant(v)
+	}
+	for len(padded) > 0 {
+		h = sha256Compress(api, h, padded[:sha256BlockSize])
+		padded = padded[sha256BlockSize:]
+	}
+
+	out := make([]frontend.Variable, 0, Sha256Size)
+	for _, w := range h {
+		out = append(out, sha256WordToBytes(api, w)...)
+	}
+	return out
+}
+
+// sha256Compress returns the state h after the block of 64 bytes
+func sha256Compress(api frontend.API, h [8]sha256Word, block []frontend.Variable) [8]sha256Word {
+	var w [64]sha256Word
+	for t := 0; t < 16; t++ {
+		w[t] = sha256WordFromBytes(api, block[4*t:4*t+4])
+	}
+	for t := 16; t < 64; t++ {
+		s0 := sha256Xor3(api, sha256Rotr(w[t-15], 7), sha256Rotr(w[t-15], 18), sha256Shr(w[t-15], 3))
+		s1 := sha256Xor3(api, sha256Rotr(w[t-2], 17), sha256Rotr(w[t-2], 19), sha256Shr(w[t-2], 10))
+		w[t] = sha256Add(api, s1, w[t-7], s0, w[t-16])
+	}
+
+	a, b, c, d, e, f, g, hh := h[0], h[1], h[2], h[3], h[4], h[5], h[6], h[7]
+	for t := 0; t < 64; t++ {
+		bigS1 := sha256Xor3(api, sha256Rotr(e, 6), sha256Rotr(e, 11), sha256Rotr(e, 25))
+		bigS0 := sha256Xor3(api, sha256Rotr(a, 2), sha256Rotr(a, 13), sha256Rotr(a, 22))
+		var ch, maj sha256Word
+		for i := range ch {
+			// ch = e ? f : g, maj = a ^ b ? c : a
+			ch[i] = api.Add(g[i], api.Mul(e[i], api.Sub(f[i], g[i])))
+			maj[i] = api.Select(api.Xor(a[i], b[i]), c[i], a[i])
+		}
+		t1 := []frontend.Variable{sha256Value(api, hh), sha256Value(api, bigS1), sha256Value(api, ch), sha256K[t], sha256Value(api, w[t])}
+		t2 := []frontend.Variable{sha256Value(api, bigS0), sha256Value(api, maj)}
+		hh, g, f = g, f, e
+		e = sha256AddValues(api, append([]frontend.Variable{sha256Value(api, d)}, t1...)...)
+		d, c, b = c, b, a
+		a = sha256AddValues(api, append(t1, t2...)...)
+	}
+
+	for i, v := range [8]sha256Word{a, b, c, d, e, f, g, hh} {
+		h[i] = sha256Add(api, h[i], v)
+	}
+	return h
+}
+
+func sha256Constant(v uint32) (w sha256Word) {
+	for i := range w {
+		w[i] = (v >> i) & 1
+	}
+	return w
+}
+
+// sha256WordFromBytes returns the big-endian word of 4 bytes
+func sha256WordFromBytes(api frontend.API, bytes []frontend.Variable) (w sha256Word) {
+	copy(w[:], bytesToBits(api, []frontend.Variable{bytes[3], bytes[2], bytes[1], bytes[0]}))
+	return w
+}
+
+// sha256WordToBytes returns the 4 big-endian bytes of the word
+func sha256WordToBytes(api frontend.API, w sha256Word) []frontend.Variable {
+	bytes := bitsToBytes(api, w[:])
+	return []frontend.Variable{bytes[3], bytes[2], bytes[1], bytes[0]}
+}
+
+func sha256Rotr(w sha256Word, n int) (r sha256Word) {
+	for i := range r {
+		r[i] = w[(i+n)%32]
+	}
+	return r
+}
+
+func sha256Shr(w sha256Word, n int) (r sha256Word) {
+	for i := range r {
+		if i+n < 32 {
+			r[i] = w[i+n]
+		} else {
+			r[i] = 0
+		}
+	}
+	return r
+}
+
+func sha256Xor3(api frontend.API, a, b, c sha256Word) (r sha256Word) {
+	for i := range r {
+		r[i] = api.Xor(api.Xor(a[i], b[i]), c[i])
+	}
+	return r
+}
+
+// sha256Value returns the value of the word, without constraints: the bits are known to be boolean
+func sha256Value(api frontend.API, w sha256Word) frontend.Variable {
+	var v frontend.Variable = 0
+	for i := len(w) - 1; i >= 0; i-- {
+		v = api.Add(api.Mul(v, 2), w[i])
+	}
+	return v
+}
+
+func sha256Add(api frontend.API, words ...sha256Word) sha256Word {
+	values := make([]frontend.Variable, len(words))
+	for i, w := range words {
+		values[i] = sha256Value(api, w)
+	}
+	return sha256AddValues(api, values...)
+}
+
+// sha256AddValues returns the sum modulo 2³² of 32-bit values: the low bits of the decomposition of the sum, which has
+// at most 32 + ⌈log₂(len(values))⌉ bits
+func sha256AddValues(api frontend.API, values ...frontend.Variable) (w sha256Word) {
+	bits := 32
+	for 1<<(bits-32) < len(values) {
+		bits++
+	}
+	sum := api.Add(values[0], values[1], values[2:]...)
+	copy(w[:], api.ToBinary(sum, bits))
+	return w
+}
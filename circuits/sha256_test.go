@@ -0,0 +1,101 @@
+package circuits
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"math/rand"
+	"testing"
+)
+
+type sha256Circuit struct {
+	M      []frontend.Variable
+	Hash   [Sha256Size]frontend.Variable `gnark:",public"`
+	double bool
+}
+
+func (c *sha256Circuit) Define(api frontend.API) error {
+	hash := Sha256(api, c.M)
+	if c.double {
+		hash = Sha256(api, hash)
+	}
+	for i := range hash {
+		api.AssertIsEqual(hash[i], c.Hash[i])
+	}
+	return nil
+}
+
+func sha256Solved(m, hash []byte, double bool) error {
+	circuit := &sha256Circuit{M: make([]frontend.Variable, len(m)), double: double}
+	witness := &sha256Circuit{M: make([]frontend.Variable, len(m))}
+	for i := range m {
+		witness.M[i] = m[i]
+	}
+	for i := range hash {
+		witness.Hash[i] = hash[i]
+	}
+	return test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16)
+}
+
+func TestSha256(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// FIPS 180-4 examples, the last one has 2 blocks
+	vectors := []struct{ m, hash string }{
+		{"", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq", "248d6a61d20638b8e5c026930c3e6039a33ce45964ff2167f6ecedd419db06c1"},
+	}
+	for _, v := range vectors {
+		hash, err := hex.DecodeString(v.hash)
+		assert.NoError(err)
+		assert.NoError(sha256Solved([]byte(v.m), hash, false), "%q", v.m)
+	}
+
+	// around the block boundaries, the padding takes 1 or 2 blocks
+	rng := rand.New(rand.NewSource(1))
+	for _, length := range []int{1, 55, 56, 63, 64, 119, 120, 200} {
+		m := make([]byte, length)
+		rng.Read(m)
+		hash := sha256.Sum256(m)
+		assert.NoError(sha256Solved(m, hash[:], false), "%d bytes", length)
+
+		hash[0] ^= 1
+		assert.Error(sha256Solved(m, hash[:], false), "%d bytes", length)
+	}
+
+	// double SHA-256
+	m := []byte("hello")
+	first := sha256.Sum256(m)
+	hash := sha256.Sum256(first[:])
+	assert.NoError(sha256Solved(m, hash[:], true))
+}
+
+func TestSha256Constraints(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, blocks := range []int{1, 2} {
+		circuit := &sha256Circuit{M: make([]frontend.Variable, blocks*sha256BlockSize-9)}
+		cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, circuit)
+		assert.NoError(err)
+		assert.Less(cs.GetNbConstraints(), blocks*32000, "%d blocks", blocks)
+
+		// the R1CS solver agrees with the test engine
+		m := make([]byte, len(circuit.M))
+		hash := sha256.Sum256(m)
+		witness := &sha256Circuit{M: make([]frontend.Variable, len(m))}
+		for i := range m {
+			witness.M[i] = m[i]
+		}
+		for i := range hash {
+			witness.Hash[i] = hash[i]
+		}
+		w, err := frontend.NewWitness(witness, ecc.BN254)
+		assert.NoError(err)
+		assert.NoError(cs.IsSolved(w), "%d blocks", blocks)
+	}
+}
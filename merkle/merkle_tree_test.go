@@ -3,8 +3,15 @@ package merkle_tree_test
 import (
 	"fmt"
 	"github.com/cbergoon/merkletree"
+	gnarkMerkleTree "github.com/consensys/gnark-crypto/accumulator/merkletree"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
+	"gnark-bid/circuits"
+	merkle "gnark-bid/merkle"
 	"golang.org/x/crypto/sha3"
 	"log"
 	"math/big"
@@ -84,3 +91,186 @@ func TestNewMerkleTree(t *testing.T) {
 	//	log.Println(common.Bytes2Hex(p))
 	//}
 }
+
+type poseidonMerkleCircuit struct {
+	Root         frontend.Variable `gnark:",public"`
+	Path, Helper []frontend.Variable
+}
+
+func (c *poseidonMerkleCircuit) Define(api frontend.API) error {
+	circuits.VerifyPoseidonMerkleProof(api, c.Root, c.Path, c.Helper)
+	return nil
+}
+
+func TestPoseidonMerkleTree(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, nbLeaves := range []int{1, 2, 5, 8} {
+		var leaves [][]byte
+		for i := 0; i < nbLeaves; i++ {
+			leaves = append(leaves, []byte(fmt.Sprintf("user=%d|room=1111", i)))
+		}
+		tree, err := merkle.NewMerkleTreeBytesPoseidon(leaves)
+		assert.NoError(err)
+
+		for i, h := range tree.Hashes {
+			// the leaves are the Poseidon of their data
+			expected, err := circuits.NativePoseidon([]*big.Int{new(big.Int).SetBytes(leaves[i])})
+			assert.NoError(err)
+			assert.Equal(expected, new(big.Int).SetBytes(h))
+
+			root, proof, helper, err := tree.BuilderProofHelper(h)
+			assert.NoError(err)
+			assert.True(gnarkMerkleTree.VerifyProof(tree.HashFunc(), root, proof, uint64(i), uint64(nbLeaves)))
+
+			circuit := &poseidonMerkleCircuit{Path: make([]frontend.Variable, len(proof)), Helper: make([]frontend.Variable, len(helper))}
+			witness := &poseidonMerkleCircuit{Root: root, Path: make([]frontend.Variable, len(proof)), Helper: make([]frontend.Variable, len(helper))}
+			for j := range proof {
+				witness.Path[j] = proof[j]
+			}
+			for j := range helper {
+				witness.Helper[j] = helper[j]
+			}
+			assert.NoError(test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16), "%d leaves, leaf %d", nbLeaves, i)
+
+			witness.Root = new(big.Int).Add(new(big.Int).SetBytes(root), big.NewInt(1))
+			assert.Error(test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16))
+		}
+	}
+
+	// a node is Poseidon(left, right)
+	tree, err := merkle.NewMerkleTreeBytesPoseidon([][]byte{[]byte("a"), []byte("b")})
+	assert.NoError(err)
+	root, _, _, err := tree.BuilderProofHelper(tree.Hashes[0])
+	assert.NoError(err)
+	leaf := func(h []byte) *big.Int {
+		l, err := circuits.NativePoseidon([]*big.Int{new(big.Int).SetBytes(h)})
+		assert.NoError(err)
+		return l
+	}
+	expected, err := circuits.NativePoseidon([]*big.Int{leaf(tree.Hashes[0]), leaf(tree.Hashes[1])})
+	assert.NoError(err)
+	assert.Equal(expected, new(big.Int).SetBytes(root))
+
+	// a leaf is one element, the data of two would hash like a node
+	_, err = merkle.NewMerkleTreeBytesPoseidon([][]byte{make([]byte, merkle.MaxLeafData)})
+	assert.NoError(err)
+	node := append(leaf(tree.Hashes[0]).FillBytes(make([]byte, 32)), leaf(tree.Hashes[1]).FillBytes(make([]byte, 32))...)
+	_, err = merkle.NewMerkleTreeBytesPoseidon([][]byte{[]byte("a"), node})
+	assert.ErrorIs(err, merkle.ErrLeafTooLong)
+}
+
+type hashMerkleCircuit struct {
+	Root         frontend.Variable `gnark:",public"`
+	Path, Helper []frontend.Variable
+	hash         circuits.HashID
+}
+
+func (c *hashMerkleCircuit) Define(api frontend.API) error {
+	hf, err := circuits.LookupHash(c.hash)
+	if err != nil {
+		return err
+	}
+	h, err := hf.NewGadget(api)
+	if err != nil {
+		return err
+	}
+	circuits.VerifyMerkleProof(api, h, c.Root, c.Path, c.Helper)
+	return nil
+}
+
+func TestMerkleTreeHashes(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	leaves := [][]byte{[]byte("user=1|room=1111"), []byte("user=2|room=1111"), []byte("user=3|room=1111")}
+	for _, id := range circuits.HashIDs() {
+		tree, err := merkle.NewMerkleTreeBytesHash(leaves, id)
+		assert.NoError(err)
+		assert.Equal(id, tree.Hash())
+		hf, err := circuits.LookupHash(id)
+		assert.NoError(err)
+
+		for i, h := range tree.Hashes {
+			// the leaves are the hashes of their data
+			native := hf.New()
+			native.Write(leaves[i])
+			assert.Equal(native.Sum(nil), h)
+
+			root, proof, helper, err := tree.BuilderProofHelper(h)
+			assert.NoError(err)
+			circuit := &hashMerkleCircuit{Path: make([]frontend.Variable, len(proof)), Helper: make([]frontend.Variable, len(helper)), hash: id}
+			witness := &hashMerkleCircuit{Root: root, Path: make([]frontend.Variable, len(proof)), Helper: make([]frontend.Variable, len(helper))}
+			for j := range proof {
+				witness.Path[j] = proof[j]
+			}
+			for j := range helper {
+				witness.Helper[j] = helper[j]
+			}
+			assert.NoError(test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16), "%s, leaf %d", id, i)
+
+			witness.Root = new(big.Int).Add(new(big.Int).SetBytes(root), big.NewInt(1))
+			assert.Error(test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16), "%s, leaf %d", id, i)
+		}
+	}
+
+	_, err := merkle.NewMerkleTreeBytesHash(leaves, "md5")
+	assert.Error(err)
+	for _, id := range circuits.HashIDs() {
+		_, err := merkle.NewMerkleTreeBytesHash([][]byte{make([]byte, merkle.MaxLeafData+1)}, id)
+		assert.ErrorIs(err, merkle.ErrLeafTooLong, id)
+	}
+}
+
+type keccakMerkleCircuit struct {
+	Root  [circuits.KeccakHashSize]frontend.Variable `gnark:",public"`
+	Leaf  [circuits.KeccakHashSize]frontend.Variable
+	Proof [][circuits.KeccakHashSize]frontend.Variable
+}
+
+func (c *keccakMerkleCircuit) Define(api frontend.API) error {
+	circuits.VerifyKeccakMerkleProof(api, c.Root, c.Leaf, c.Proof)
+	return nil
+}
+
+func keccakHashVariables(h []byte) (v [circuits.KeccakHashSize]frontend.Variable) {
+	for i := range v {
+		v[i] = h[i]
+	}
+	return v
+}
+
+func TestKeccakMerkleTree(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, nbLeaves := range []int{1, 2, 5} {
+		var leaves [][]byte
+		for i := 0; i < nbLeaves; i++ {
+			leaves = append(leaves, []byte(fmt.Sprintf("0x%040x", i+1)))
+		}
+		tree, err := merkle.NewMerkleTreeBytes(leaves)
+		assert.NoError(err)
+		root := tree.GetRoot()
+
+		for _, leaf := range leaves {
+			leafHash, err := tree.GetLeaf(merkle.ByteContent{B: leaf})
+			assert.NoError(err)
+			proof, err := tree.GetProofByByte(leaf)
+			assert.NoError(err)
+
+			circuit := &keccakMerkleCircuit{Proof: make([][circuits.KeccakHashSize]frontend.Variable, len(proof))}
+			witness := &keccakMerkleCircuit{
+				Root:  keccakHashVariables(root),
+				Leaf:  keccakHashVariables(leafHash),
+				Proof: make([][circuits.KeccakHashSize]frontend.Variable, len(proof)),
+			}
+			for j := range proof {
+				witness.Proof[j] = keccakHashVariables(proof[j])
+			}
+			assert.NoError(test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16), "%d leaves, leaf %s", nbLeaves, leaf)
+
+			// a leaf that isn't in the tree
+			witness.Leaf[0] = leafHash[0] ^ 1
+			assert.Error(test.IsSolved(circuit, witness, ecc.BN254, backend.GROTH16))
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package merkle_tree
+
+import (
+	"gnark-bid/circuits"
+	"hash"
+)
+
+// PoseidonMaxData is the largest data hashed by NewPoseidon, 16 field elements
+const PoseidonMaxData = circuits.PoseidonMaxData
+
+// NewPoseidon returns the Poseidon hash strategy of the trees, circuits.NewPoseidonHash: the data is split in 32 bytes
+// big endian field elements reduced modulo r like MiMC, and Write fails beyond PoseidonMaxData bytes. The in-circuit
+// counterpart is circuits.PoseidonHasher.
+func NewPoseidon() hash.Hash {
+	return circuits.NewPoseidonHash()
+}
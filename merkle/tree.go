@@ -3,17 +3,31 @@ package merkle_tree
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/cbergoon/merkletree"
 	gnarkMerkleTree "github.com/consensys/gnark-crypto/accumulator/merkletree"
-	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	"github.com/consensys/gnark/std/accumulator/merkle"
 	"github.com/influxdata/influxdb/pkg/bytesutil"
 	"github.com/thoas/go-funk"
+	"gnark-bid/circuits"
+	"gnark-bid/telemetry"
 	"golang.org/x/crypto/sha3"
 	"hash"
+	"time"
 )
 
+// ErrLeafNotFound is returned when building the proof of a leaf that isn't in the tree
+var ErrLeafNotFound = errors.New("leaf not found")
+
+// ErrLeafTooLong is returned for the data of a leaf longer than MaxLeafData
+var ErrLeafTooLong = errors.New("leaf too long")
+
+// MaxLeafData is the largest data of a leaf of NewMerkleTreeBytesHash, one field element like the leaves of the
+// circuits and of the PoseidonMerkle contract. Longer data would be hashed like a node, e.g. the 64 bytes left ‖ right.
+const MaxLeafData = fr.Bytes
+
 type ByteContent struct {
 	B        []byte
 	HashFunc func() hash.Hash
@@ -25,7 +39,7 @@ type Tree struct {
 	Hashes  [][]byte
 
 	HashFunc func() hash.Hash
-	isZKTree bool
+	hash     circuits.HashID
 }
 
 // CalculateHash hashes the values of a TestContent
@@ -43,11 +57,8 @@ func (c ByteContent) Equals(other merkletree.Content) (bool, error) {
 	return bytes.Equal(c.B, other.(ByteContent).B), nil
 }
 
-func newMerkleTree(contents []ByteContent, isZKTree bool) (*Tree, error) {
-	hashStrategy := sha3.NewLegacyKeccak256
-	if isZKTree {
-		hashStrategy = mimc.NewMiMC
-	}
+func newMerkleTree(contents []ByteContent, hashStrategy func() hash.Hash, id circuits.HashID) (*Tree, error) {
+	start := time.Now()
 
 	var list []merkletree.Content
 	var hashes [][]byte
@@ -62,15 +73,17 @@ func newMerkleTree(contents []ByteContent, isZKTree bool) (*Tree, error) {
 
 	merkleTree, err := merkletree.NewTreeWithHashStrategySorted(list, hashStrategy, true)
 	if err != nil {
+		telemetry.Log().Error("building merkle tree failed", "leaves", len(list), "hash", id, "err", err)
 		return nil, err
 	}
+	telemetry.Log().Debug("merkle tree built", "leaves", len(list), "hash", id, "took", time.Since(start))
 
 	return &Tree{
 		MerkleTree: merkleTree,
 		Content:    list,
 		Hashes:     hashes,
 		HashFunc:   hashStrategy,
-		isZKTree:   isZKTree,
+		hash:       id,
 	}, nil
 }
 
@@ -79,15 +92,40 @@ func NewMerkleTreeBytes(bs [][]byte) (*Tree, error) {
 
 	return newMerkleTree(funk.Map(bs, func(b []byte) ByteContent {
 		return ByteContent{B: b, HashFunc: sha3.NewLegacyKeccak256}
-	}).([]ByteContent), false)
+	}).([]ByteContent), sha3.NewLegacyKeccak256, "")
+}
+
+// NewMerkleTreeBytesHash builds a tree hashed with the native hash of the registry, the leaves are the hashes of the
+// data, at most MaxLeafData bytes. Its proofs (BuilderProofHelper) are verified by circuits.VerifyMerkleProof with the
+// gadget of the same hash.
+func NewMerkleTreeBytesHash(bs [][]byte, id circuits.HashID) (*Tree, error) {
+	h, err := circuits.LookupHash(id)
+	if err != nil {
+		return nil, err
+	}
+	for i, b := range bs {
+		if len(b) > MaxLeafData {
+			return nil, fmt.Errorf("%w: leaf %d has %d bytes, at most %d", ErrLeafTooLong, i, len(b), MaxLeafData)
+		}
+	}
+	return newMerkleTree(funk.Map(bs, func(b []byte) ByteContent {
+		return ByteContent{B: b, HashFunc: h.New}
+	}).([]ByteContent), h.New, id)
 }
 
 func NewMerkleTreeBytesZK(bs [][]byte) (*Tree, error) {
-	//bytesutil.Sort(bs)
+	return NewMerkleTreeBytesHash(bs, circuits.HashMiMC)
+}
 
-	return newMerkleTree(funk.Map(bs, func(b []byte) ByteContent {
-		return ByteContent{B: b, HashFunc: mimc.NewMiMC}
-	}).([]ByteContent), true)
+// NewMerkleTreeBytesPoseidon builds a tree hashed with NewPoseidon, the leaves are the Poseidon of the data. Its
+// proofs (BuilderProofHelper) are verified by circuits.VerifyPoseidonMerkleProof and by the PoseidonMerkle contract.
+func NewMerkleTreeBytesPoseidon(bs [][]byte) (*Tree, error) {
+	return NewMerkleTreeBytesHash(bs, circuits.HashPoseidon)
+}
+
+// Hash returns the hash of the tree in the registry, empty for the sorted keccak trees of NewMerkleTreeBytes
+func (t *Tree) Hash() circuits.HashID {
+	return t.hash
 }
 
 func (t *Tree) GetRoot() []byte {
@@ -144,16 +182,22 @@ func (t *Tree) GetHexProof(c ByteContent) ([]string, error) {
 
 func (t *Tree) BuilderProofFromLeafByte(leafHash []byte) (merkleRoot []byte, merkleProof [][]byte, proofIndex uint64, err error) {
 	var buf bytes.Buffer
+	found := false
 	for i, h := range t.Hashes {
 		buf.Write(h)
-		if bytes.Equal(h, leafHash) {
+		if !found && bytes.Equal(h, leafHash) {
 			proofIndex = uint64(i)
+			found = true
 		}
 	}
+	if !found {
+		return nil, nil, 0, fmt.Errorf("%w: %x", ErrLeafNotFound, leafHash)
+	}
 	//fmt.Println("proofIndex", proofIndex)
 	segmentSize := 32
 	merkleRoot, merkleProof, _, err = gnarkMerkleTree.BuildReaderProof(&buf, t.HashFunc(), segmentSize, proofIndex)
 	if err != nil {
+		telemetry.Log().Debug("building merkle proof failed", "index", proofIndex, "err", err)
 		return nil, nil, proofIndex, err
 	}
 	return merkleRoot, merkleProof, proofIndex, nil
@@ -20,6 +20,11 @@
 
 // 2019 OKIMS
 
+// Circuit: BiddingCircuit v1
+// VK sha256: 41f2f512e69b63cb6662585da2831f3f33e14fe852e464f9279e66a9c11e13d7
+// R1CS sha256: 47dae16ac17e8810bc2b0547413ad527c95c91b299c7dfa90a3c7fa94e036e8d
+// Fingerprint: 0x0cc498e67bf58651bb21889e6c82a36932716d9f0f5b91da01ebac27df19c141
+
 pragma solidity ^0.8.0;
 
 library Pairing {
@@ -145,6 +150,8 @@ library Pairing {
 
 contract Verifier {
 
+    bytes32 public constant FINGERPRINT = 0x0cc498e67bf58651bb21889e6c82a36932716d9f0f5b91da01ebac27df19c141;
+
     using Pairing for *;
 
     uint256 constant SNARK_SCALAR_FIELD = 21888242871839275222246405745257275088548364400416034343698204186575808495617;
@@ -0,0 +1,273 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package solidity
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// PoseidonMerkleMetaData contains all meta data concerning the PoseidonMerkle contract.
+var PoseidonMerkleMetaData = &bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"leafHash\",\"stateMutability\":\"pure\",\"inputs\":[{\"name\":\"leaf\",\"type\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"nodeHash\",\"stateMutability\":\"pure\",\"inputs\":[{\"name\":\"left\",\"type\":\"uint256\"},{\"name\":\"right\",\"type\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"verify\",\"stateMutability\":\"pure\",\"inputs\":[{\"name\":\"root\",\"type\":\"uint256\"},{\"name\":\"proofSet\",\"type\":\"uint256[]\"},{\"name\":\"helper\",\"type\":\"uint256[]\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}]}]",
+}
+
+// PoseidonMerkleABI is the input ABI used to generate the binding from.
+// Deprecated: Use PoseidonMerkleMetaData.ABI instead.
+var PoseidonMerkleABI = PoseidonMerkleMetaData.ABI
+
+// PoseidonMerkle is an auto generated Go binding around an Ethereum contract.
+type PoseidonMerkle struct {
+	PoseidonMerkleCaller     // Read-only binding to the contract
+	PoseidonMerkleTransactor // Write-only binding to the contract
+	PoseidonMerkleFilterer   // Log filterer for contract events
+}
+
+// PoseidonMerkleCaller is an auto generated read-only Go binding around an Ethereum contract.
+type PoseidonMerkleCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PoseidonMerkleTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type PoseidonMerkleTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PoseidonMerkleFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type PoseidonMerkleFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PoseidonMerkleSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type PoseidonMerkleSession struct {
+	Contract     *PoseidonMerkle   // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// PoseidonMerkleCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type PoseidonMerkleCallerSession struct {
+	Contract *PoseidonMerkleCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts         // Call options to use throughout this session
+}
+
+// PoseidonMerkleTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type PoseidonMerkleTransactorSession struct {
+	Contract     *PoseidonMerkleTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts         // Transaction auth options to use throughout this session
+}
+
+// PoseidonMerkleRaw is an auto generated low-level Go binding around an Ethereum contract.
+type PoseidonMerkleRaw struct {
+	Contract *PoseidonMerkle // Generic contract binding to access the raw methods on
+}
+
+// PoseidonMerkleCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type PoseidonMerkleCallerRaw struct {
+	Contract *PoseidonMerkleCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// PoseidonMerkleTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type PoseidonMerkleTransactorRaw struct {
+	Contract *PoseidonMerkleTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewPoseidonMerkle creates a new instance of PoseidonMerkle, bound to a specific deployed contract.
+func NewPoseidonMerkle(address common.Address, backend bind.ContractBackend) (*PoseidonMerkle, error) {
+	contract, err := bindPoseidonMerkle(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &PoseidonMerkle{PoseidonMerkleCaller: PoseidonMerkleCaller{contract: contract}, PoseidonMerkleTransactor: PoseidonMerkleTransactor{contract: contract}, PoseidonMerkleFilterer: PoseidonMerkleFilterer{contract: contract}}, nil
+}
+
+// NewPoseidonMerkleCaller creates a new read-only instance of PoseidonMerkle, bound to a specific deployed contract.
+func NewPoseidonMerkleCaller(address common.Address, caller bind.ContractCaller) (*PoseidonMerkleCaller, error) {
+	contract, err := bindPoseidonMerkle(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PoseidonMerkleCaller{contract: contract}, nil
+}
+
+// NewPoseidonMerkleTransactor creates a new write-only instance of PoseidonMerkle, bound to a specific deployed contract.
+func NewPoseidonMerkleTransactor(address common.Address, transactor bind.ContractTransactor) (*PoseidonMerkleTransactor, error) {
+	contract, err := bindPoseidonMerkle(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PoseidonMerkleTransactor{contract: contract}, nil
+}
+
+// NewPoseidonMerkleFilterer creates a new log filterer instance of PoseidonMerkle, bound to a specific deployed contract.
+func NewPoseidonMerkleFilterer(address common.Address, filterer bind.ContractFilterer) (*PoseidonMerkleFilterer, error) {
+	contract, err := bindPoseidonMerkle(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &PoseidonMerkleFilterer{contract: contract}, nil
+}
+
+// bindPoseidonMerkle binds a generic wrapper to an already deployed contract.
+func bindPoseidonMerkle(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(PoseidonMerkleABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_PoseidonMerkle *PoseidonMerkleRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _PoseidonMerkle.Contract.PoseidonMerkleCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_PoseidonMerkle *PoseidonMerkleRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _PoseidonMerkle.Contract.PoseidonMerkleTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_PoseidonMerkle *PoseidonMerkleRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _PoseidonMerkle.Contract.PoseidonMerkleTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_PoseidonMerkle *PoseidonMerkleCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _PoseidonMerkle.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_PoseidonMerkle *PoseidonMerkleTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _PoseidonMerkle.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_PoseidonMerkle *PoseidonMerkleTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _PoseidonMerkle.Contract.contract.Transact(opts, method, params...)
+}
+
+// LeafHash is a free data retrieval call binding the contract method 0x22f54aaf.
+//
+// Solidity: function leafHash(uint256 leaf) pure returns(uint256)
+func (_PoseidonMerkle *PoseidonMerkleCaller) LeafHash(opts *bind.CallOpts, leaf *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _PoseidonMerkle.contract.Call(opts, &out, "leafHash", leaf)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// LeafHash is a free data retrieval call binding the contract method 0x22f54aaf.
+//
+// Solidity: function leafHash(uint256 leaf) pure returns(uint256)
+func (_PoseidonMerkle *PoseidonMerkleSession) LeafHash(leaf *big.Int) (*big.Int, error) {
+	return _PoseidonMerkle.Contract.LeafHash(&_PoseidonMerkle.CallOpts, leaf)
+}
+
+// LeafHash is a free data retrieval call binding the contract method 0x22f54aaf.
+//
+// Solidity: function leafHash(uint256 leaf) pure returns(uint256)
+func (_PoseidonMerkle *PoseidonMerkleCallerSession) LeafHash(leaf *big.Int) (*big.Int, error) {
+	return _PoseidonMerkle.Contract.LeafHash(&_PoseidonMerkle.CallOpts, leaf)
+}
+
+// NodeHash is a free data retrieval call binding the contract method 0xc54f97b9.
+//
+// Solidity: function nodeHash(uint256 left, uint256 right) pure returns(uint256)
+func (_PoseidonMerkle *PoseidonMerkleCaller) NodeHash(opts *bind.CallOpts, left *big.Int, right *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _PoseidonMerkle.contract.Call(opts, &out, "nodeHash", left, right)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// NodeHash is a free data retrieval call binding the contract method 0xc54f97b9.
+//
+// Solidity: function nodeHash(uint256 left, uint256 right) pure returns(uint256)
+func (_PoseidonMerkle *PoseidonMerkleSession) NodeHash(left *big.Int, right *big.Int) (*big.Int, error) {
+	return _PoseidonMerkle.Contract.NodeHash(&_PoseidonMerkle.CallOpts, left, right)
+}
+
+// NodeHash is a free data retrieval call binding the contract method 0xc54f97b9.
+//
+// Solidity: function nodeHash(uint256 left, uint256 right) pure returns(uint256)
+func (_PoseidonMerkle *PoseidonMerkleCallerSession) NodeHash(left *big.Int, right *big.Int) (*big.Int, error) {
+	return _PoseidonMerkle.Contract.NodeHash(&_PoseidonMerkle.CallOpts, left, right)
+}
+
+// Verify is a free data retrieval call binding the contract method 0xbc031865.
+//
+// Solidity: function verify(uint256 root, uint256[] proofSet, uint256[] helper) pure returns(bool)
+func (_PoseidonMerkle *PoseidonMerkleCaller) Verify(opts *bind.CallOpts, root *big.Int, proofSet []*big.Int, helper []*big.Int) (bool, error) {
+	var out []interface{}
+	err := _PoseidonMerkle.contract.Call(opts, &out, "verify", root, proofSet, helper)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// Verify is a free data retrieval call binding the contract method 0xbc031865.
+//
+// Solidity: function verify(uint256 root, uint256[] proofSet, uint256[] helper) pure returns(bool)
+func (_PoseidonMerkle *PoseidonMerkleSession) Verify(root *big.Int, proofSet []*big.Int, helper []*big.Int) (bool, error) {
+	return _PoseidonMerkle.Contract.Verify(&_PoseidonMerkle.CallOpts, root, proofSet, helper)
+}
+
+// Verify is a free data retrieval call binding the contract method 0xbc031865.
+//
+// Solidity: function verify(uint256 root, uint256[] proofSet, uint256[] helper) pure returns(bool)
+func (_PoseidonMerkle *PoseidonMerkleCallerSession) Verify(root *big.Int, proofSet []*big.Int, helper []*big.Int) (bool, error) {
+	return _PoseidonMerkle.Contract.Verify(&_PoseidonMerkle.CallOpts, root, proofSet, helper)
+}
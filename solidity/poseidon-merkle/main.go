@@ -0,0 +1,25 @@
+// Command poseidon-merkle writes PoseidonMerkle.sol, the Poseidon contract of the trees of
+// merkle.NewMerkleTreeBytesPoseidon with the constants of circuits.PoseidonReferenceConstants (`make abigen-poseidon`
+// then compiles it with solc and writes the Go binding)
+package main
+
+import (
+	"flag"
+	"gnark-bid/solidity"
+	"log"
+	"os"
+)
+
+func main() {
+	out := flag.String("out", "PoseidonMerkle.sol", "Solidity source")
+	flag.Parse()
+
+	source, err := solidity.PoseidonMerkleSource()
+	if err != nil {
+		log.Fatal("template error:", err)
+	}
+	if err := os.WriteFile(*out, source, 0o644); err != nil {
+		log.Fatal("write error:", err)
+	}
+	log.Println("wrote", *out)
+}
@@ -0,0 +1,136 @@
+package solidity
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/suite"
+	"gnark-bid/circuits"
+	merkle "gnark-bid/merkle"
+	"math/big"
+	"os"
+	"testing"
+)
+
+type ExportSolidityTestSuitePoseidonMerkle struct {
+	suite.Suite
+
+	backend  *backends.SimulatedBackend
+	contract *PoseidonMerkle
+}
+
+func TestRunExportSolidityTestSuitePoseidonMerkle(t *testing.T) {
+	suite.Run(t, new(ExportSolidityTestSuitePoseidonMerkle))
+}
+
+func (t *ExportSolidityTestSuitePoseidonMerkle) SetupTest() {
+	const gasLimit uint64 = 8000000
+
+	// the bytecode is compiled by solc from PoseidonMerkle.sol
+	if PoseidonMerkleMetaData.Bin == "" {
+		t.T().Skip("PoseidonMerkle.go has no bytecode, run make abigen")
+	}
+	parsed, err := PoseidonMerkleMetaData.GetAbi()
+	t.NoError(err, "parse abi")
+
+	key, _ := crypto.GenerateKey()
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	t.NoError(err, "init keyed transactor")
+
+	genesis := map[common.Address]core.GenesisAccount{
+		auth.From: {Balance: big.NewInt(1000000000000000000)}, // 1 Eth
+	}
+	t.backend = backends.NewSimulatedBackend(genesis, gasLimit)
+
+	address, _, _, err := bind.DeployContract(auth, *parsed, common.FromHex(PoseidonMerkleMetaData.Bin), t.backend)
+	t.NoError(err, "deploy contract failed")
+	t.contract, err = NewPoseidonMerkle(address, t.backend)
+	t.NoError(err, "bind contract failed")
+	t.backend.Commit()
+}
+
+func (t *ExportSolidityTestSuitePoseidonMerkle) TestHashes() {
+	for i := int64(0); i < 4; i++ {
+		a, b := big.NewInt(i), big.NewInt(1000+i)
+
+		expected, err := circuits.NativePoseidon([]*big.Int{a})
+		t.NoError(err)
+		h, err := t.contract.LeafHash(nil, a)
+		t.NoError(err, "call leafHash failed")
+		t.Equal(expected, h)
+
+		expected, err = circuits.NativePoseidon([]*big.Int{a, b})
+		t.NoError(err)
+		h, err = t.contract.NodeHash(nil, a, b)
+		t.NoError(err, "call nodeHash failed")
+		t.Equal(expected, h)
+	}
+
+	// the inputs must be reduced
+	_, err := t.contract.LeafHash(nil, fr.Modulus())
+	t.Error(err)
+}
+
+func (t *ExportSolidityTestSuitePoseidonMerkle) TestVerifyProof() {
+	var leaves [][]byte
+	for i := 0; i < 13; i++ {
+		leaves = append(leaves, []byte(fmt.Sprintf("user=%d|room=1111", i)))
+	}
+	tree, err := merkle.NewMerkleTreeBytesPoseidon(leaves)
+	t.NoError(err, "create merkle tree failed")
+
+	for i, h := range tree.Hashes {
+		root, proof, helper, err := tree.BuilderProofHelper(h)
+		t.NoError(err)
+		rootInt := new(big.Int).SetBytes(root)
+		proofSet := make([]*big.Int, len(proof))
+		for j := range proof {
+			proofSet[j] = new(big.Int).SetBytes(proof[j])
+		}
+		helperInts := make([]*big.Int, len(helper))
+		for j := range helper {
+			helperInts[j] = big.NewInt(int64(helper[j]))
+		}
+
+		verified, err := t.contract.Verify(nil, rootInt, proofSet, helperInts)
+		t.NoError(err, "call verify failed")
+		t.True(verified, "leaf %d", i)
+
+		verified, err = t.contract.Verify(nil, new(big.Int).Add(rootInt, big.NewInt(1)), proofSet, helperInts)
+		t.NoError(err)
+		t.False(verified)
+
+		if len(helperInts) > 0 {
+			helperInts[0] = big.NewInt(1 - int64(helper[0]))
+			verified, err = t.contract.Verify(nil, rootInt, proofSet, helperInts)
+			t.NoError(err)
+			t.False(verified)
+
+			// malformed proofs revert
+			helperInts[0] = big.NewInt(2)
+			_, err = t.contract.Verify(nil, rootInt, proofSet, helperInts)
+			t.Error(err)
+			_, err = t.contract.Verify(nil, rootInt, proofSet, helperInts[1:])
+			t.Error(err)
+		}
+	}
+}
+
+func TestPoseidonMerkleSourceIsGenerated(t *testing.T) {
+	source, err := PoseidonMerkleSource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	onDisk, err := os.ReadFile("PoseidonMerkle.sol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(source, onDisk) {
+		t.Error("PoseidonMerkle.sol is outdated, run go run ./poseidon-merkle")
+	}
+}
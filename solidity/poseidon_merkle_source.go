@@ -0,0 +1,180 @@
+package solidity
+
+import (
+	"bytes"
+	"fmt"
+	"gnark-bid/circuits"
+	"math/big"
+	"text/template"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// poseidonMerkleTemplate is PoseidonMerkle.sol: the reference Poseidon permutation (circuits.PoseidonReferenceConstants)
+// like circomlib's Poseidon contracts, with the constants of the permutations with 1 and 2 inputs
+var poseidonMerkleTemplate = template.Must(template.New("PoseidonMerkle.sol").Parse(`// SPDX-License-Identifier: MIT
+// Code generated by go run ./poseidon-merkle from circuits.PoseidonReferenceConstants. DO NOT EDIT.
+pragma solidity ^0.8.17;
+
+// IPoseidonMerkle is Poseidon over BN254 like circuits.Poseidon and the trees of merkle.NewMerkleTreeBytesPoseidon.
+// The functions revert on inputs that are not reduced modulo r and on malformed proofs.
+interface IPoseidonMerkle {
+    // Poseidon(leaf), the leaf of a tree from the hash of its content
+    function leafHash(uint256 leaf) external pure returns (uint256);
+
+    // Poseidon(left, right), a node of a tree
+    function nodeHash(uint256 left, uint256 right) external pure returns (uint256);
+
+    // verify returns true when proofSet is the proof of proofSet[0] in the tree of root, helper[i-1] is 1 when
+    // proofSet[i] is a right child (merkle.Tree.BuilderProofHelper and circuits.VerifyPoseidonMerkleProof)
+    function verify(
+        uint256 root,
+        uint256[] calldata proofSet,
+        uint256[] calldata helper
+    ) external pure returns (bool);
+}
+
+// PoseidonPermutation is the reference Poseidon permutation: each round adds t constants to the state, applies x⁵ to
+// the whole state in the full rounds (the first and last roundsF/2) and to state[0] in the partial rounds, then mixes
+// the state with state[i] = Σⱼ m[j][i] * state[j]. The constants are 32 bytes big-endian words, m row by row.
+library PoseidonPermutation {
+    uint256 internal constant Q = {{.Modulus}};
+
+    function hash(
+        uint256[] memory inputs,
+        bytes memory c,
+        bytes memory m,
+        uint256 roundsF,
+        uint256 roundsP
+    ) internal pure returns (uint256) {
+        uint256 t = inputs.length + 1;
+        uint256[] memory state = new uint256[](t);
+        for (uint256 i = 1; i < t; i++) {
+            require(inputs[i - 1] < Q, "PoseidonMerkle: input not reduced");
+            state[i] = inputs[i - 1];
+        }
+
+        uint256[] memory mixed = new uint256[](t);
+        for (uint256 r = 0; r < roundsF + roundsP; r++) {
+            for (uint256 i = 0; i < t; i++) {
+                state[i] = addmod(state[i], word(c, r * t + i), Q);
+            }
+            if (r < roundsF / 2 || r >= roundsF / 2 + roundsP) {
+                for (uint256 i = 0; i < t; i++) {
+                    state[i] = sbox(state[i]);
+                }
+            } else {
+                state[0] = sbox(state[0]);
+            }
+            for (uint256 i = 0; i < t; i++) {
+                uint256 sum = 0;
+                for (uint256 j = 0; j < t; j++) {
+                    sum = addmod(sum, mulmod(word(m, j * t + i), state[j], Q), Q);
+                }
+                mixed[i] = sum;
+            }
+            (state, mixed) = (mixed, state);
+        }
+        return state[0];
+    }
+
+    function sbox(uint256 x) private pure returns (uint256) {
+        uint256 x2 = mulmod(x, x, Q);
+        return mulmod(mulmod(x2, x2, Q), x, Q);
+    }
+
+    // word returns the i-th 32 bytes word of data
+    function word(bytes memory data, uint256 i) private pure returns (uint256 w) {
+        require((i + 1) * 32 <= data.length, "PoseidonMerkle: constant out of range");
+        assembly {
+            w := mload(add(data, mul(add(i, 1), 32)))
+        }
+    }
+}
+{{range .Permutations}}
+// PoseidonT{{.T}} is Poseidon with {{.NInputs}} input{{if gt .NInputs 1}}s{{end}}, {{.RoundsF}} full and {{.RoundsP}} partial rounds
+library PoseidonT{{.T}} {
+    function hash(uint256[{{.NInputs}}] memory inputs) internal pure returns (uint256) {
+        uint256[] memory state = new uint256[]({{.NInputs}});
+        for (uint256 i = 0; i < {{.NInputs}}; i++) {
+            state[i] = inputs[i];
+        }
+        return PoseidonPermutation.hash(state, c(), m(), {{.RoundsF}}, {{.RoundsP}});
+    }
+
+    function c() private pure returns (bytes memory) {
+        return{{range .C}}
+            hex"{{.}}"{{end}};
+    }
+
+    function m() private pure returns (bytes memory) {
+        return{{range .M}}
+            hex"{{.}}"{{end}};
+    }
+}
+{{end}}
+contract PoseidonMerkle is IPoseidonMerkle {
+    function leafHash(uint256 leaf) external pure returns (uint256) {
+        return PoseidonT2.hash([leaf]);
+    }
+
+    function nodeHash(uint256 left, uint256 right) external pure returns (uint256) {
+        return PoseidonT3.hash([left, right]);
+    }
+
+    function verify(
+        uint256 root,
+        uint256[] calldata proofSet,
+        uint256[] calldata helper
+    ) external pure returns (bool) {
+        require(proofSet.length > 0 && helper.length + 1 == proofSet.length, "PoseidonMerkle: malformed proof");
+        uint256 sum = PoseidonT2.hash([proofSet[0]]);
+        for (uint256 i = 1; i < proofSet.length; i++) {
+            require(helper[i - 1] < 2, "PoseidonMerkle: malformed proof");
+            if (helper[i - 1] == 1) {
+                sum = PoseidonT3.hash([sum, proofSet[i]]);
+            } else {
+                sum = PoseidonT3.hash([proofSet[i], sum]);
+            }
+        }
+        return sum == root;
+    }
+}
+`))
+
+type poseidonPermutationSource struct {
+	T, NInputs, RoundsF, RoundsP int
+	// C and M are the hexadecimal words of the constants, M row by row
+	C, M []string
+}
+
+// PoseidonMerkleSource returns PoseidonMerkle.sol, `go run ./poseidon-merkle` writes it
+func PoseidonMerkleSource() ([]byte, error) {
+	hexWord := func(v *big.Int) string {
+		return fmt.Sprintf("%064x", v)
+	}
+	data := struct {
+		Modulus      string
+		Permutations []poseidonPermutationSource
+	}{Modulus: fr.Modulus().String()}
+	for nInputs := 1; nInputs <= 2; nInputs++ {
+		c, m := circuits.PoseidonReferenceConstants(nInputs)
+		roundsF, roundsP := circuits.PoseidonRounds(nInputs)
+		p := poseidonPermutationSource{T: nInputs + 1, NInputs: nInputs, RoundsF: roundsF, RoundsP: roundsP}
+		for _, v := range c {
+			p.C = append(p.C, hexWord(v))
+		}
+		for _, row := range m {
+			for _, v := range row {
+				p.M = append(p.M, hexWord(v))
+			}
+		}
+		data.Permutations = append(data.Permutations, p)
+	}
+
+	var buf bytes.Buffer
+	if err := poseidonMerkleTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -1,6 +1,7 @@
 package solidity
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
@@ -8,6 +9,8 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/iden3/go-iden3-crypto/poseidon"
 	"github.com/thoas/go-funk"
@@ -15,6 +18,7 @@ import (
 	"gnark-bid/zk"
 	zkCircuit "gnark-bid/zk/circuits"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -117,7 +121,7 @@ func (t *ExportSolidityTestSuiteBiddingVerifier) TestVerifyProof() {
 
 	assert.ProverSucceeded(&circuit, &merkleAssignment, test.WithCurves(ecc.BN254))
 
-	proofParser, g16Proof, err := t.g16.GenerateProof(&merkleAssignment)
+	proofParser, g16Proof, err := t.g16.GenerateProof(context.Background(), &merkleAssignment)
 	assert.NoError(err, "proving failed")
 	fmt.Println("proof", proofParser)
 	fmt.Println("g16Proof", g16Proof)
@@ -140,7 +144,7 @@ func (t *ExportSolidityTestSuiteBiddingVerifier) TestVerifyProof() {
 func (t *ExportSolidityTestSuiteBiddingVerifier) TestBidding() {
 	assert := test.NewAssert(t.Suite.T())
 
-	bidding, err := zk.NewBidding()
+	bidding, err := zk.NewBidding(nil)
 	assert.NoError(err, "creating bidding failed")
 	assert.NoError(bidding.InitSession(1111, "username_2", big.NewInt(1111222233334444)), "init session failed")
 
@@ -159,3 +163,106 @@ func (t *ExportSolidityTestSuiteBiddingVerifier) TestBidding() {
 
 	fmt.Println("verifier on chain succeeded")
 }
+
+func (t *ExportSolidityTestSuiteBiddingVerifier) TestVerifyProofCalldata() {
+	assert := test.NewAssert(t.Suite.T())
+
+	bidding, err := zk.NewBidding(nil)
+	assert.NoError(err, "creating bidding failed")
+	assert.NoError(bidding.InitSession(1111, "username_2", big.NewInt(1111222233334444)), "init session failed")
+
+	proof, inputs, err := bidding.GetProof(big.NewInt(100))
+	assert.NoError(err, "creating proof failed")
+
+	calldata, err := zk.GenerateCalldata(BiddingCircuitMetaData.ABI, proof, inputs[:])
+	assert.NoError(err, "generating calldata failed")
+
+	parsed, err := BiddingCircuitMetaData.GetAbi()
+	assert.NoError(err, "parsing abi failed")
+	packed, err := parsed.Pack("verifyProof", proof.A, proof.B, proof.C, inputs)
+	assert.NoError(err, "packing with abi failed")
+	assert.Equal(packed, calldata.Data, "calldata should match the generated bindings")
+
+	// call the contract with raw calldata
+	out, err := t.backend.CallContract(context.Background(), ethereum.CallMsg{To: &t.address, Data: calldata.Data}, nil)
+	assert.NoError(err, "calling verifier on chain gave error")
+	res, err := parsed.Unpack("verifyProof", out)
+	assert.NoError(err, "unpacking result failed")
+	assert.Equal([]interface{}{true}, res, "calling verifier on chain didn't succeed")
+
+	// wrong number of public inputs
+	_, err = zk.GenerateCalldata(BiddingCircuitMetaData.ABI, proof, inputs[:4])
+	assert.ErrorIs(err, zk.ErrInvalidPublicInput, "calldata with missing public input should fail")
+
+	// malformed values are rejected before abi.Pack, which panics on nil
+	_, err = zk.GenerateCalldata(BiddingCircuitMetaData.ABI, nil, inputs[:])
+	assert.ErrorIs(err, zk.ErrProofInvalid, "calldata without proof should fail")
+	malformed := *proof
+	malformed.B[1][0] = nil
+	_, err = zk.GenerateCalldata(BiddingCircuitMetaData.ABI, &malformed, inputs[:])
+	assert.ErrorIs(err, zk.ErrProofInvalid, "calldata with a nil proof coordinate should fail")
+	malformed = *proof
+	malformed.A[0] = big.NewInt(-1)
+	_, err = zk.GenerateCalldata(BiddingCircuitMetaData.ABI, &malformed, inputs[:])
+	assert.ErrorIs(err, zk.ErrProofInvalid, "calldata with a negative proof coordinate should fail")
+
+	badInputs := append([]*big.Int(nil), inputs[:]...)
+	badInputs[2] = nil
+	_, err = zk.GenerateCalldata(BiddingCircuitMetaData.ABI, proof, badInputs)
+	assert.ErrorIs(err, zk.ErrInvalidPublicInput, "calldata with a nil public input should fail")
+	badInputs[2] = big.NewInt(-5)
+	_, err = zk.GenerateCalldata(BiddingCircuitMetaData.ABI, proof, badInputs)
+	assert.ErrorIs(err, zk.ErrInvalidPublicInput, "calldata with a negative public input should fail")
+}
+
+func (t *ExportSolidityTestSuiteBiddingVerifier) TestFingerprint() {
+	assert := test.NewAssert(t.Suite.T())
+
+	bundle, err := zk.DefaultBundle()
+	assert.NoError(err, "loading bundle failed")
+	fp, err := bundle.Fingerprint("BiddingCircuit")
+	assert.NoError(err, "bundle has no fingerprint")
+	assert.Equal(BiddingCircuitFingerprint, fp.Digest().Hex(), "bindings and bundle fingerprints differ")
+
+	bidding, err := zk.NewBidding(nil)
+	assert.NoError(err, "creating bidding failed")
+	assert.NoError(bidding.InitSession(1111, "username_2", big.NewInt(1111222233334444)), "init session failed")
+
+	// keys regenerated without the contract
+	assert.ErrorIs(bidding.SetVerifierFingerprint(common.HexToHash("0x01")), zk.ErrFingerprintMismatch)
+	_, _, err = bidding.GetProof(big.NewInt(100))
+	assert.ErrorIs(err, zk.ErrFingerprintMismatch, "proving for a mismatched verifier should fail")
+
+	assert.NoError(bidding.SetVerifierFingerprint(common.HexToHash(BiddingCircuitFingerprint)))
+	proof, inputs, err := bidding.GetProof(big.NewInt(100))
+	assert.NoError(err, "creating proof failed")
+	res, err := t.contract.VerifyProof(nil, proof.A, proof.B, proof.C, inputs)
+	assert.NoError(err, "calling verifier on chain gave error")
+	assert.True(res, "calling verifier on chain didn't succeed")
+}
+
+func (t *ExportSolidityTestSuiteBiddingVerifier) TestDeployedFingerprint() {
+	assert := test.NewAssert(t.Suite.T())
+	ctx := context.Background()
+
+	// no contract, no fingerprint
+	_, err := zk.ReadVerifierFingerprint(ctx, t.backend, common.HexToAddress("0x01"))
+	assert.Error(err, "reading the fingerprint of an empty account should fail")
+
+	if !strings.Contains(BiddingCircuitBin, strings.TrimPrefix(BiddingCircuitFingerprint, "0x")) {
+		t.T().Skip("abi/Contract_BiddingCircuit_sol_Verifier.bin predates the FINGERPRINT constant, run make abigen")
+	}
+	digest, err := zk.ReadVerifierFingerprint(ctx, t.backend, t.address)
+	assert.NoError(err, "reading the fingerprint of the verifier failed")
+	assert.Equal(BiddingCircuitFingerprint, digest.Hex(), "deployed and bindings fingerprints differ")
+
+	bidding, err := zk.NewBidding(nil)
+	assert.NoError(err, "creating bidding failed")
+	assert.NoError(bidding.InitSession(1111, "username_2", big.NewInt(1111222233334444)), "init session failed")
+	assert.NoError(bidding.UseVerifier(ctx, t.backend, t.address), "the deployed verifier should match the keys")
+	proof, inputs, err := bidding.GetProof(big.NewInt(100))
+	assert.NoError(err, "creating proof failed")
+	res, err := t.contract.VerifyProof(nil, proof.A, proof.B, proof.C, inputs)
+	assert.NoError(err, "calling verifier on chain gave error")
+	assert.True(res, "calling verifier on chain didn't succeed")
+}
@@ -1,6 +1,7 @@
 package solidity
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"github.com/consensys/gnark-crypto/accumulator/merkletree"
@@ -74,7 +75,7 @@ func (t *ExportSolidityTestSuiteMerkleVerifier) TestVerifyProof() {
 
 	assert.ProverSucceeded(&circuit, &merkleAssignment, test.WithCurves(ecc.BN254))
 
-	proofParser, g16Proof, err := t.g16.GenerateProof(&merkleAssignment)
+	proofParser, g16Proof, err := t.g16.GenerateProof(context.Background(), &merkleAssignment)
 	assert.NoError(err, "proving failed")
 	fmt.Println("proof", proofParser)
 	fmt.Println("g16Proof", g16Proof)
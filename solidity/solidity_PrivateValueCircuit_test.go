@@ -1,6 +1,7 @@
 package solidity
 
 import (
+	"context"
 	"fmt"
 	"github.com/consensys/gnark/test"
 	"gnark-bid/zk/circuits"
@@ -34,7 +35,7 @@ func (t *ExportSolidityTestSuiteGroth16) TestVerifyProof() {
 	assignment.PrivateValue = privValue
 	assignment.Hash = zk_circuit.HashMIMC(big.NewInt(privValue).Bytes())
 
-	proofParser, g16Proof, err := t.g16.GenerateProof(&assignment)
+	proofParser, g16Proof, err := t.g16.GenerateProof(context.Background(), &assignment)
 	assert.NoError(err, "proving failed")
 
 	// hidden witness
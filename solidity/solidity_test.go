@@ -22,6 +22,7 @@ type ExportSolidityTestSuite[T any] struct {
 
 	// contract
 	contract *T
+	address  common.Address
 
 	// groth16 gnark objects
 	vk  groth16.VerifyingKey
@@ -30,11 +31,20 @@ type ExportSolidityTestSuite[T any] struct {
 }
 
 func InitSetup[T any](t *ExportSolidityTestSuite[T], circuit frontend.Circuit, deployFunc func(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *T, error), vpName string) {
+	require := t.Require()
+
+	// the embedded bundle only ships the keys of the circuits used in production
+	bundle, err := zk.DefaultBundle()
+	require.NoError(err, "loading bundle failed")
+	if _, err := bundle.Circuit(vpName); err != nil {
+		t.T().Skipf("%s: not in the embedded bundle", vpName)
+	}
+
 	const gasLimit uint64 = 4712388
 	// setup simulated backend
 	key, _ := crypto.GenerateKey()
 	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
-	t.NoError(err, "init keyed transactor")
+	require.NoError(err, "init keyed transactor")
 
 	genesis := map[common.Address]core.GenesisAccount{
 		auth.From: {Balance: big.NewInt(1000000000000000000)}, // 1 Eth
@@ -42,13 +52,14 @@ func InitSetup[T any](t *ExportSolidityTestSuite[T], circuit frontend.Circuit, d
 	t.backend = backends.NewSimulatedBackend(genesis, gasLimit)
 
 	// deploy verifier contract
-	_, _, v, err := deployFunc(auth, t.backend)
-	t.NoError(err, "deploy verifier contract failed")
+	addr, _, v, err := deployFunc(auth, t.backend)
+	require.NoError(err, "deploy verifier contract failed")
 	t.contract = v
+	t.address = addr
 	t.backend.Commit()
 
-	vpKey, err := zk.GetVPKey(vpName)
-	t.NoError(err, "getting vpkey failed")
+	vpKey, err := bundle.VPKey(vpName)
+	require.NoError(err, "getting vpkey failed")
 
 	// read proving and verifying keys
 	t.pk = vpKey.PK
@@ -56,5 +67,5 @@ func InitSetup[T any](t *ExportSolidityTestSuite[T], circuit frontend.Circuit, d
 
 	// create gnark groth16
 	t.g16, err = zk.NewGnarkGroth16(vpKey, circuit)
-	t.NoError(err, "init groth16 failed")
+	require.NoError(err, "init groth16 failed")
 }
@@ -0,0 +1,83 @@
+// Package telemetry holds the logger and the metrics used by the zk, merkle and wasm packages. Both are silent until
+// SetLogger and SetMetrics are called.
+package telemetry
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Logger is the subset of log/slog.Logger used by this module, a *slog.Logger can be passed as is.
+// args are alternating keys and values.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// Metrics receives the measurements of the provers and verifiers. Implementations must be safe for concurrent use,
+// embed NopMetrics to only implement some of them.
+type Metrics interface {
+	// ProofPhase is the duration of one phase of a proof (witness, prove, verify)
+	ProofPhase(circuit, phase string, d time.Duration)
+	// ProofGenerated is the total duration of a proof
+	ProofGenerated(circuit string, d time.Duration)
+	// ConstraintCount is the number of constraints of a circuit loaded by a prover
+	ConstraintCount(circuit string, nbConstraints int)
+	// VerificationFailed is a rejected proof, reason is a short identifier (witness, pairing, batch, ...)
+	VerificationFailed(circuit, reason string)
+}
+
+// NopLogger discards everything
+type NopLogger struct{}
+
+func (NopLogger) Debug(string, ...any) {}
+func (NopLogger) Info(string, ...any)  {}
+func (NopLogger) Warn(string, ...any)  {}
+func (NopLogger) Error(string, ...any) {}
+
+// NopMetrics discards everything
+type NopMetrics struct{}
+
+func (NopMetrics) ProofPhase(string, string, time.Duration) {}
+func (NopMetrics) ProofGenerated(string, time.Duration)     {}
+func (NopMetrics) ConstraintCount(string, int)              {}
+func (NopMetrics) VerificationFailed(string, string)        {}
+
+// the interfaces are boxed so that atomic.Value always stores the same concrete type
+type loggerBox struct{ Logger }
+type metricsBox struct{ Metrics }
+
+var logger, metrics atomic.Value
+
+func init() {
+	logger.Store(loggerBox{NopLogger{}})
+	metrics.Store(metricsBox{NopMetrics{}})
+}
+
+// SetLogger sets the logger of the module, nil silences it
+func SetLogger(l Logger) {
+	if l == nil {
+		l = NopLogger{}
+	}
+	logger.Store(loggerBox{l})
+}
+
+// SetMetrics sets the metrics of the module, nil discards them
+func SetMetrics(m Metrics) {
+	if m == nil {
+		m = NopMetrics{}
+	}
+	metrics.Store(metricsBox{m})
+}
+
+// Log returns the logger of the module
+func Log() Logger {
+	return logger.Load().(loggerBox).Logger
+}
+
+// Stats returns the metrics of the module
+func Stats() Metrics {
+	return metrics.Load().(metricsBox).Metrics
+}
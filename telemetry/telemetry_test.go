@@ -0,0 +1,32 @@
+package telemetry
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewTextLogger(&buf, LevelInfo)
+	l.now = func() time.Time { return time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC) }
+
+	l.Debug("hidden")
+	l.Info("proof generated", "circuit", "BiddingCircuit", "took", 2*time.Second)
+	l.Error("verify failed", "err", errors.New("pairing check failed"), "dangling")
+	assert.Equal(t, "time=2022-09-01T00:00:00Z level=INFO msg=\"proof generated\" circuit=BiddingCircuit took=2s\n"+
+		"time=2022-09-01T00:00:00Z level=ERROR msg=\"verify failed\" err=\"pairing check failed\" !BADKEY=dangling\n", buf.String())
+}
+
+func TestDefaults(t *testing.T) {
+	assert.IsType(t, NopLogger{}, Log())
+	assert.IsType(t, NopMetrics{}, Stats())
+
+	l := NewTextLogger(&bytes.Buffer{}, LevelDebug)
+	SetLogger(l)
+	assert.Equal(t, Logger(l), Log())
+	SetLogger(nil)
+	assert.IsType(t, NopLogger{}, Log())
+}
@@ -0,0 +1,86 @@
+package telemetry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Level is the severity of a log record, with the values of log/slog
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return "LEVEL(" + strconv.Itoa(int(l)) + ")"
+}
+
+// TextLogger writes one `time=... level=... msg=... key=value` line per record at or above its level, for programs
+// that can't use log/slog
+type TextLogger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level Level
+	now   func() time.Time
+}
+
+// NewTextLogger returns a logger writing to w the records at or above level
+func NewTextLogger(w io.Writer, level Level) *TextLogger {
+	return &TextLogger{w: w, level: level, now: time.Now}
+}
+
+func (l *TextLogger) Debug(msg string, args ...any) { l.log(LevelDebug, msg, args) }
+func (l *TextLogger) Info(msg string, args ...any)  { l.log(LevelInfo, msg, args) }
+func (l *TextLogger) Warn(msg string, args ...any)  { l.log(LevelWarn, msg, args) }
+func (l *TextLogger) Error(msg string, args ...any) { l.log(LevelError, msg, args) }
+
+func (l *TextLogger) log(level Level, msg string, args []any) {
+	if level < l.level {
+		return
+	}
+	var buf bytes.Buffer
+	buf.WriteString("time=")
+	buf.WriteString(l.now().Format(time.RFC3339))
+	buf.WriteString(" level=")
+	buf.WriteString(level.String())
+	buf.WriteString(" msg=")
+	buf.WriteString(quote(msg))
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			// same as slog for a dangling value
+			fmt.Fprintf(&buf, " !BADKEY=%s", quote(fmt.Sprint(args[i])))
+			break
+		}
+		fmt.Fprintf(&buf, " %s=%s", fmt.Sprint(args[i]), quote(fmt.Sprint(args[i+1])))
+	}
+	buf.WriteByte('\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.w.Write(buf.Bytes())
+}
+
+func quote(s string) string {
+	if s == "" || bytes.ContainsAny([]byte(s), " =\"\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"gnark-bid/telemetry"
+	"strings"
+	"syscall/js"
+)
+
+// consoleLogger writes the records at or above its level to the browser console
+type consoleLogger struct {
+	console js.Value
+	level   telemetry.Level
+}
+
+func (l consoleLogger) Debug(msg string, args ...any) {
+	l.log(telemetry.LevelDebug, "debug", msg, args)
+}
+
+func (l consoleLogger) Info(msg string, args ...any) {
+	l.log(telemetry.LevelInfo, "info", msg, args)
+}
+
+func (l consoleLogger) Warn(msg string, args ...any) {
+	l.log(telemetry.LevelWarn, "warn", msg, args)
+}
+
+func (l consoleLogger) Error(msg string, args ...any) {
+	l.log(telemetry.LevelError, "error", msg, args)
+}
+
+func (l consoleLogger) log(level telemetry.Level, method, msg string, args []any) {
+	if level < l.level {
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString(msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&sb, " %v=%v", args[i], args[i+1])
+	}
+	l.console.Call(method, sb.String())
+}
+
+// setLogLevel("debug" | "info" | "warn" | "error" | "off") logs the bidding to the console, it is off by default
+func setLogLevel() js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) != 1 {
+			return jsErr(nil, "Invalid no of arguments passed")
+		}
+
+		levels := map[string]telemetry.Level{
+			"debug": telemetry.LevelDebug,
+			"info":  telemetry.LevelInfo,
+			"warn":  telemetry.LevelWarn,
+			"error": telemetry.LevelError,
+		}
+		name := strings.ToLower(args[0].String())
+		if name == "off" {
+			telemetry.SetLogger(nil)
+			return fmt.Sprintf("{'status': '%s','message': '%s'}", "success", "Logs disabled")
+		}
+		level, ok := levels[name]
+		if !ok {
+			return jsErr(nil, "Invalid log level")
+		}
+		telemetry.SetLogger(consoleLogger{console: js.Global().Get("console"), level: level})
+		return fmt.Sprintf("{'status': '%s','message': '%s'}", "success", "Log level set")
+	})
+}
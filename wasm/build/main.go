@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
+	"gnark-bid/telemetry"
 	"gnark-bid/zk"
 	"math/big"
 	"syscall/js"
@@ -21,10 +22,10 @@ func init() {
 func initBidding() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) any {
 		if bidding != nil {
-			return jsErr(nil, "Session already initialized")
+			return jsErrCode(codeAlreadyInitialized, "Session already initialized")
 		}
 
-		fmt.Println("Initializing bidding")
+		telemetry.Log().Info("initializing bidding")
 		if b, err := zk.NewBidding(nil); err != nil {
 			return jsErr(err, "Cannot init bidding")
 		} else {
@@ -38,7 +39,7 @@ func initBidding() js.Func {
 func createSession() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) any {
 		if bidding == nil {
-			return jsErr(nil, "Session not initialized")
+			return jsErrCode(codeNotInitialized, "Session not initialized")
 		}
 
 		if len(args) != 3 {
@@ -62,7 +63,7 @@ func createSession() js.Func {
 func renewSession() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) any {
 		if bidding == nil {
-			return jsErr(nil, "Session not initialized")
+			return jsErrCode(codeNotInitialized, "Session not initialized")
 		}
 
 		err := bidding.RenewSession()
@@ -82,27 +83,29 @@ func isInitialized() js.Func {
 func generateProof() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) any {
 		if bidding == nil {
-			return jsErr(nil, "Session not initialized")
+			return jsErrCode(codeNotInitialized, "Session not initialized")
 		}
 
 		if len(args) != 1 {
 			return jsErr(nil, "Invalid no of arguments passed")
 		}
 		if err := validation.Var(args[0].String(), "required,hexadecimal"); err != nil {
-			return jsErr(err, "Invalid argument input passed")
+			return jsArgErr(err, "Invalid argument input passed")
 		}
 
 		inputBytes := common.FromHex(args[0].String())
 		bidValue := new(big.Int).SetBytes(inputBytes)
 
-		proofs, inputs, err := bidding.GetProof(bidValue)
+		proofs, inputs, err := bidding.GetProofInputs(bidValue)
 		if err != nil {
 			return jsErr(err, "Cannot generate proof")
 		}
 
+		// inputs is the vector of the Solidity verifier, publicInputs names them
 		data := map[string]interface{}{
-			"proofs": proofs,
-			"inputs": inputs,
+			"proofs":       proofs,
+			"inputs":       inputs.Vector(),
+			"publicInputs": inputs,
 		}
 		dataJSON, _ := json.Marshal(data)
 
@@ -113,7 +116,7 @@ func generateProof() js.Func {
 func joinRoom() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) any {
 		if bidding == nil {
-			return jsErr(nil, "Session not initialized")
+			return jsErrCode(codeNotInitialized, "Session not initialized")
 		}
 
 		if len(args) != 1 {
@@ -131,7 +134,7 @@ func joinRoom() js.Func {
 func getCurrentSession() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) any {
 		if bidding == nil {
-			return jsErr(nil, "Session not initialized")
+			return jsErrCode(codeNotInitialized, "Session not initialized")
 		}
 
 		data := map[string]interface{}{
@@ -151,20 +154,33 @@ func getCurrentSession() js.Func {
 
 func verifyProof() js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) any {
+		if bidding == nil {
+			return jsErrCode(codeNotInitialized, "Session not initialized")
+		}
+
 		if len(args) != 1 {
 			return jsErr(nil, "Invalid no of arguments passed")
 		}
 
+		// publicInputs (named) is preferred, inputs is the ordered vector of the schema
 		var data struct {
-			Proofs *zk.Proof   `json:"proofs"`
-			Inputs [5]*big.Int `json:"inputs"`
+			Proofs       *zk.Proof        `json:"proofs"`
+			Inputs       []*big.Int       `json:"inputs"`
+			PublicInputs *zk.PublicInputs `json:"publicInputs"`
 		}
 
 		if err := json.Unmarshal([]byte(args[0].String()), &data); err != nil {
-			return jsErr(err, "Cannot unmarshal data")
+			return jsArgErr(err, "Cannot unmarshal data")
+		}
+		inputs := data.PublicInputs
+		if inputs == nil {
+			var err error
+			if inputs, err = bidding.PublicInputSchema().PublicInputs(data.Inputs); err != nil {
+				return jsErr(err, "Invalid public inputs")
+			}
 		}
 
-		verified, err := bidding.VerifyProof(data.Proofs, data.Inputs)
+		verified, err := bidding.VerifyProofInputs(data.Proofs, inputs)
 		if err != nil {
 			return jsErr(err, "Cannot verify proof")
 		}
@@ -172,9 +188,24 @@ func verifyProof() js.Func {
 	})
 }
 
+func getPublicInputSchema() js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) any {
+		if bidding == nil {
+			return jsErrCode(codeNotInitialized, "Session not initialized")
+		}
+
+		dataJSON, err := json.Marshal(bidding.PublicInputSchema())
+		if err != nil {
+			return jsErr(err, "Cannot marshal data")
+		}
+		return string(dataJSON)
+	})
+}
+
 func main() {
-	fmt.Println("Go Web Assembly - Bidding Platform")
+	telemetry.Log().Info("Go Web Assembly - Bidding Platform")
 
+	js.Global().Set("setLogLevel", setLogLevel())
 	js.Global().Set("isInitialized", isInitialized())
 	js.Global().Set("initBidding", initBidding())
 	js.Global().Set("createSession", createSession())
@@ -183,6 +214,7 @@ func main() {
 	js.Global().Set("joinRoom", joinRoom())
 	js.Global().Set("generateProof", generateProof())
 	js.Global().Set("verifyProof", verifyProof())
+	js.Global().Set("getPublicInputSchema", getPublicInputSchema())
 
 	<-make(chan bool)
 }
@@ -4,25 +4,46 @@ import (
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/go-playground/validator/v10"
+	"gnark-bid/zk"
 	"math/big"
 	"strconv"
 )
 
 var validation = validator.New()
 
+// error codes of the bindings themselves, the errors of zk have the codes of zk.ErrorCode
+const (
+	codeInvalidArgument    zk.Code = "INVALID_ARGUMENT"
+	codeNotInitialized     zk.Code = "NOT_INITIALIZED"
+	codeAlreadyInitialized zk.Code = "ALREADY_INITIALIZED"
+)
+
 func jsErr(err error, message string) string {
-	if message == "" {
-		return fmt.Sprintf("{'error': '%s','message': '%s'}", err.Error(), message)
-	}
 	if err == nil {
-		return fmt.Sprintf("{'error': '%s'}", message)
+		return jsErrCode(codeInvalidArgument, message)
+	}
+	return jsErrWithCode(err, message, zk.ErrorCode(err))
+}
+
+// jsArgErr reports an invalid argument (validation, unmarshalling)
+func jsArgErr(err error, message string) string {
+	return jsErrWithCode(err, message, codeInvalidArgument)
+}
+
+func jsErrCode(code zk.Code, message string) string {
+	return fmt.Sprintf("{'error': '%s','code': '%s'}", message, code)
+}
+
+func jsErrWithCode(err error, message string, code zk.Code) string {
+	if message == "" {
+		return fmt.Sprintf("{'error': '%s','message': '%s','code': '%s'}", err.Error(), message, code)
 	}
-	return fmt.Sprintf("{'error': '%s'}", err.Error())
+	return fmt.Sprintf("{'error': '%s','code': '%s'}", err.Error(), code)
 }
 
 func parserHexToBigInt(arg string) (*big.Int, string) {
 	if err := validation.Var(arg, "required,hexadecimal"); err != nil {
-		return nil, jsErr(err, "Invalid argument input passed")
+		return nil, jsArgErr(err, "Invalid argument input passed")
 	}
 
 	value := common.FromHex(arg)
@@ -32,7 +53,7 @@ func parserHexToBigInt(arg string) (*big.Int, string) {
 func parserStringToInt(arg string) (int, string) {
 	i, err := strconv.Atoi(arg)
 	if err != nil {
-		return -1, jsErr(err, "Invalid argument input passed")
+		return -1, jsArgErr(err, "Invalid argument input passed")
 	}
 	return i, ""
 }
@@ -0,0 +1,189 @@
+package zk
+
+import (
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"math/big"
+	"sort"
+)
+
+// BatchVerify verifies proofs made with the same verifying key. The proofs are checked together with a random linear
+// combination and a single multi-pairing:
+//
+//	Π e(rᵢ·Aᵢ, Bᵢ) · e(-Σrᵢ·Lᵢ, γ) · e(-Σrᵢ·Cᵢ, δ) · e(-(Σrᵢ)·α, β) = 1
+//
+// with Lᵢ = K₀ + Σⱼ xᵢⱼ·Kⱼ the public inputs term. When the batch fails, it is split in halves until the invalid proofs
+// are found. It returns the sorted indexes of the invalid proofs, err is only set for an unusable verifying key.
+func BatchVerify(vk groth16.VerifyingKey, proofs []groth16.Proof, publicWitnesses []*witness.Witness) ([]int, error) {
+	if len(proofs) != len(publicWitnesses) {
+		return nil, fmt.Errorf("%d proofs and %d public witnesses", len(proofs), len(publicWitnesses))
+	}
+	_vk, err := verifyingKeyBN254(vk)
+	if err != nil {
+		return nil, err
+	}
+
+	var invalid []int
+	batch := make([]batchEntry, 0, len(proofs))
+	for i := range proofs {
+		entry, err := newBatchEntry(i, _vk, proofs[i], publicWitnesses[i])
+		if err != nil {
+			invalid = append(invalid, i)
+			continue
+		}
+		batch = append(batch, entry)
+	}
+
+	invalid = append(invalid, bisectBatch(_vk, batch)...)
+	sort.Ints(invalid)
+	return invalid, nil
+}
+
+// BatchVerifyProofs verifies the proofs of the assignments with a single multi-pairing, see BatchVerify. The public
+// inputs of each assignment are checked first like in VerifyProof: an assignment with a non canonical input (x + r
+// aliasing x) or no witness is reported as invalid, it would only verify once gnark reduced it.
+func (t *GnarkGroth16) BatchVerifyProofs(assignments []frontend.Circuit, proofs []groth16.Proof) ([]int, error) {
+	publicWitnesses := make([]*witness.Witness, len(assignments))
+	rejected := make(map[int]bool)
+	for i, assignment := range assignments {
+		if err := validateAssignment(assignment); err != nil {
+			t.verificationFailed("public_input", fmt.Errorf("proof %d of the batch: %w", i, err))
+			rejected[i] = true
+			continue
+		}
+		publicWitness, err := frontend.NewWitness(assignment, ecc.BN254, frontend.PublicOnly())
+		if err != nil {
+			t.verificationFailed("witness", fmt.Errorf("proof %d of the batch: %w", i, err))
+			rejected[i] = true
+			continue
+		}
+		publicWitnesses[i] = publicWitness
+	}
+	// the rejected entries have no public witness, BatchVerify reports them
+	invalid, err := BatchVerify(t.vk, proofs, publicWitnesses)
+	for _, i := range invalid {
+		if !rejected[i] {
+			t.verificationFailed("batch", fmt.Errorf("proof %d of the batch is invalid", i))
+		}
+	}
+	return invalid, err
+}
+
+type batchEntry struct {
+	index  int
+	proof  *bn254Proof
+	inputs []fr.Element
+}
+
+func newBatchEntry(index int, vk *bn254VerifyingKey, proof groth16.Proof, publicWitness *witness.Witness) (batchEntry, error) {
+	if proof == nil || publicWitness == nil {
+		return batchEntry{}, fmt.Errorf("missing proof or public witness")
+	}
+	_proof, err := proofBN254(proof)
+	if err != nil {
+		return batchEntry{}, err
+	}
+	inputs, err := witnessBN254(publicWitness)
+	if err != nil {
+		return batchEntry{}, err
+	}
+	if len(inputs) != len(*vk.G1K)-1 {
+		return batchEntry{}, fmt.Errorf("got %d public inputs, expected %d", len(inputs), len(*vk.G1K)-1)
+	}
+	// same subgroup checks as groth16.Verify
+	if !_proof.Ar.IsInSubGroup() || !_proof.Krs.IsInSubGroup() || !_proof.Bs.IsInSubGroup() {
+		return batchEntry{}, fmt.Errorf("proof points are not in the correct subgroup")
+	}
+	return batchEntry{index: index, proof: _proof, inputs: inputs}, nil
+}
+
+// bisectBatch returns the indexes of the invalid entries of a batch
+func bisectBatch(vk *bn254VerifyingKey, batch []batchEntry) []int {
+	if len(batch) == 0 {
+		return nil
+	}
+	if ok, err := verifyBatch(vk, batch); err == nil && ok {
+		return nil
+	}
+	if len(batch) == 1 {
+		return []int{batch[0].index}
+	}
+	half := len(batch) / 2
+	return append(bisectBatch(vk, batch[:half]), bisectBatch(vk, batch[half:])...)
+}
+
+func verifyBatch(vk *bn254VerifyingKey, batch []batchEntry) (bool, error) {
+	n := len(batch)
+	nbInputs := len(*vk.G1K) - 1
+
+	// random coefficients, a single proof doesn't need one
+	r := make([]fr.Element, n)
+	if n == 1 {
+		r[0].SetOne()
+	} else {
+		for i := range r {
+			if _, err := r[i].SetRandom(); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	g1 := make([]bn254.G1Affine, 0, n+3)
+	g2 := make([]bn254.G2Affine, 0, n+3)
+
+	// rᵢ·Aᵢ, Bᵢ
+	var sumR fr.Element
+	var scalar big.Int
+	for i, entry := range batch {
+		var rA bn254.G1Affine
+		rA.ScalarMultiplication(entry.proof.Ar, r[i].ToBigIntRegular(&scalar))
+		g1 = append(g1, rA)
+		g2 = append(g2, *entry.proof.Bs)
+		sumR.Add(&sumR, &r[i])
+	}
+
+	// Σrᵢ·Lᵢ = (Σrᵢ)·K₀ + Σⱼ (Σᵢ rᵢ·xᵢⱼ)·Kⱼ
+	scalars := make([]fr.Element, nbInputs+1)
+	scalars[0] = sumR
+	for i, entry := range batch {
+		for j := range entry.inputs {
+			var t fr.Element
+			t.Mul(&r[i], &entry.inputs[j])
+			scalars[j+1].Add(&scalars[j+1], &t)
+		}
+	}
+	var sumL bn254.G1Affine
+	if _, err := sumL.MultiExp(*vk.G1K, scalars, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return false, err
+	}
+	sumL.Neg(&sumL)
+	g1 = append(g1, sumL)
+	g2 = append(g2, *vk.G2Gamma)
+
+	// Σrᵢ·Cᵢ
+	krs := make([]bn254.G1Affine, n)
+	for i, entry := range batch {
+		krs[i] = *entry.proof.Krs
+	}
+	var sumC bn254.G1Affine
+	if _, err := sumC.MultiExp(krs, r, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return false, err
+	}
+	sumC.Neg(&sumC)
+	g1 = append(g1, sumC)
+	g2 = append(g2, *vk.G2Delta)
+
+	// (Σrᵢ)·α
+	var alpha bn254.G1Affine
+	alpha.ScalarMultiplication(vk.G1Alpha, sumR.ToBigIntRegular(&scalar))
+	alpha.Neg(&alpha)
+	g1 = append(g1, alpha)
+	g2 = append(g2, *vk.G2Beta)
+
+	return bn254.PairingCheck(g1, g2)
+}
@@ -0,0 +1,140 @@
+package zk_test
+
+import (
+	"context"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"gnark-bid/zk"
+	"gnark-bid/zk/circuits"
+	"gnark-bid/zk/zktest"
+	"math/big"
+	"testing"
+)
+
+type batchFixture struct {
+	vk              groth16.VerifyingKey
+	g16             *zk.GnarkGroth16
+	assignments     []frontend.Circuit
+	proofs          []groth16.Proof
+	publicWitnesses []*witness.Witness
+}
+
+func newBatchFixture(tb testing.TB, n int) *batchFixture {
+	var circuit zk_circuit.PrivateValueCircuit
+	r1csCompiled, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	pk, vk, err := zktest.UnsafeSeededSetup(r1csCompiled, []byte(tb.Name()))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	vpKey, err := zk.CreateVPKey(pk, vk)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	g16, err := zk.NewGnarkGroth16WithCS(vpKey, r1csCompiled, &circuit)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	f := &batchFixture{vk: vk, g16: g16}
+	for i := 0; i < n; i++ {
+		value := big.NewInt(int64(i + 1))
+		assignment := &zk_circuit.PrivateValueCircuit{
+			PrivateValue: value,
+			Hash:         zk_circuit.HashMIMC(value.Bytes()),
+		}
+		publicWitness, err := frontend.NewWitness(assignment, ecc.BN254, frontend.PublicOnly())
+		if err != nil {
+			tb.Fatal(err)
+		}
+		_, proof, err := g16.GenerateProof(context.Background(), assignment)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		f.assignments = append(f.assignments, assignment)
+		f.proofs = append(f.proofs, proof)
+		f.publicWitnesses = append(f.publicWitnesses, publicWitness)
+	}
+	return f
+}
+
+func TestBatchVerify(t *testing.T) {
+	assert := test.NewAssert(t)
+	f := newBatchFixture(t, 8)
+
+	invalid, err := zk.BatchVerify(f.vk, f.proofs, f.publicWitnesses)
+	assert.NoError(err)
+	assert.Empty(invalid, "all proofs should verify")
+
+	invalid, err = f.g16.BatchVerifyProofs(f.assignments, f.proofs)
+	assert.NoError(err)
+	assert.Empty(invalid, "all proofs should verify")
+
+	invalid, err = zk.BatchVerify(f.vk, f.proofs[:1], f.publicWitnesses[:1])
+	assert.NoError(err)
+	assert.Empty(invalid, "a single proof should verify")
+
+	// swapped proofs don't match their public inputs
+	proofs := append([]groth16.Proof{}, f.proofs...)
+	proofs[2], proofs[5] = proofs[5], proofs[2]
+	invalid, err = zk.BatchVerify(f.vk, proofs, f.publicWitnesses)
+	assert.NoError(err)
+	assert.Equal([]int{2, 5}, invalid)
+
+	// missing proof
+	proofs = append([]groth16.Proof{}, f.proofs...)
+	proofs[7] = nil
+	invalid, err = zk.BatchVerify(f.vk, proofs, f.publicWitnesses)
+	assert.NoError(err)
+	assert.Equal([]int{7}, invalid)
+
+	_, err = zk.BatchVerify(f.vk, f.proofs, f.publicWitnesses[1:])
+	assert.Error(err, "proofs and witnesses count should match")
+
+	// an input aliased by r verifies once reduced, VerifyProof and the batch reject it
+	assignments := append([]frontend.Circuit{}, f.assignments...)
+	aliased := *assignments[3].(*zk_circuit.PrivateValueCircuit)
+	aliased.Hash = new(big.Int).Add(aliased.Hash.(*big.Int), fr.Modulus())
+	assignments[3] = &aliased
+	_, err = f.g16.VerifyProof(&aliased, f.proofs[3])
+	assert.ErrorIs(err, zk.ErrInvalidPublicInput)
+	invalid, err = f.g16.BatchVerifyProofs(assignments, f.proofs)
+	assert.NoError(err)
+	assert.Equal([]int{3}, invalid)
+
+	// and so are the assignments that aren't a witness
+	assignments[5] = &zk_circuit.PrivateValueCircuit{Hash: "not a number"}
+	invalid, err = f.g16.BatchVerifyProofs(assignments, f.proofs)
+	assert.NoError(err)
+	assert.Equal([]int{3, 5}, invalid)
+}
+
+func benchmarkVerify(b *testing.B, n int, batch bool) {
+	f := newBatchFixture(b, n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if batch {
+			if invalid, err := zk.BatchVerify(f.vk, f.proofs, f.publicWitnesses); err != nil || len(invalid) != 0 {
+				b.Fatal("batch verification failed", invalid, err)
+			}
+			continue
+		}
+		for j := range f.proofs {
+			if err := groth16.Verify(f.proofs[j], f.vk, f.publicWitnesses[j]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkVerifySequential16(b *testing.B)  { benchmarkVerify(b, 16, false) }
+func BenchmarkVerifyBatch16(b *testing.B)       { benchmarkVerify(b, 16, true) }
+func BenchmarkVerifySequential128(b *testing.B) { benchmarkVerify(b, 128, false) }
+func BenchmarkVerifyBatch128(b *testing.B)      { benchmarkVerify(b, 128, true) }
@@ -2,14 +2,20 @@ package zk
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/frontend"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/iden3/go-iden3-crypto/poseidon"
 	"github.com/thoas/go-funk"
+	"gnark-bid/circuits"
 	merkleTree "gnark-bid/merkle"
 	zkCircuit "gnark-bid/zk/circuits"
 	"math/big"
@@ -21,6 +27,8 @@ type Identity struct {
 	Trapdoor   *big.Int
 }
 
+// Bidding is the session of one user, it is not safe for concurrent use. Servers proving for many users share one
+// GnarkGroth16 (NewBiddingWithProver) and prove the Assignment of each session with a ProverPool.
 type Bidding struct {
 	isReady     bool
 	RoomID      int
@@ -29,11 +37,42 @@ type Bidding struct {
 
 	Identity Identity
 
-	mkTree *merkleTree.Tree
-	g16    *GnarkGroth16
+	mkTree       *merkleTree.Tree
+	hash         circuits.HashID
+	g16          *GnarkGroth16
+	publicInputs *PublicInputSchema
+
+	verifierDigest *common.Hash
+}
+
+// BiddingOption configures a bidding session
+type BiddingOption func(*biddingConfig)
+
+type biddingConfig struct {
+	hash circuits.HashID
+}
+
+// WithHash hashes the users tree of the room, and the leaves, with the hash id of the registry instead of
+// zkCircuit.DefaultHash. The keys must be set up for a BiddingCircuit with the same Hash, the embedded ones are
+// DefaultHash keys.
+func WithHash(id circuits.HashID) BiddingOption {
+	return func(c *biddingConfig) {
+		c.hash = id
+	}
 }
 
-func createMerkleTree(list [][]byte) (*merkleTree.Tree, error) {
+func newBiddingConfig(opts []BiddingOption) (biddingConfig, error) {
+	cfg := biddingConfig{hash: zkCircuit.DefaultHash}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if _, err := circuits.LookupHash(cfg.hash); err != nil {
+		return biddingConfig{}, err
+	}
+	return cfg, nil
+}
+
+func createMerkleTree(list [][]byte, id circuits.HashID) (*merkleTree.Tree, error) {
 	maxLeaves := math.BigPow(2, int64(MerkleTreeDepth))
 	// generate leaves: users use this to generate their own merkle tree
 	leaves := make([][]byte, int(maxLeaves.Int64()))
@@ -44,7 +83,7 @@ func createMerkleTree(list [][]byte) (*merkleTree.Tree, error) {
 			leaves[i] = list[i]
 		}
 	}
-	mkTree, err := merkleTree.NewMerkleTreeBytesZK(leaves)
+	mkTree, err := merkleTree.NewMerkleTreeBytesHash(leaves, id)
 	if err != nil {
 		return nil, err
 	}
@@ -61,41 +100,73 @@ func fakeListTesting() [][]byte {
 	return list
 }
 
-func NewBidding(vpKey *VPKey) (*Bidding, error) {
-	mkTree, err := createMerkleTree(fakeListTesting())
-	if err != nil {
-		return nil, err
-	}
-	buf := make([]byte, 32)
-	if _, err = rand.Read(buf); err != nil {
-		return nil, err
-	}
-	nullifier, err := poseidon.HashBytes(buf)
+func NewBidding(vpKey *VPKey, opts ...BiddingOption) (*Bidding, error) {
+	cfg, err := newBiddingConfig(opts)
 	if err != nil {
 		return nil, err
 	}
+	c := zkCircuit.BiddingCircuit{Hash: cfg.hash}
+	c.UserMerklePath = make([]frontend.Variable, MerkleTreeDepth+1)
+	c.UserMerkleHelper = make([]frontend.Variable, MerkleTreeDepth)
 
-	// check time to set up
+	var g16 *GnarkGroth16
 	if vpKey == nil {
-		// Get Verifier Key and Proving Key
-		vpKey, err = GetVPKey("BiddingCircuit")
+		if cfg.hash != zkCircuit.DefaultHash {
+			return nil, fmt.Errorf("%w: the embedded keys hash with %s, not %s", ErrKeyMismatch, zkCircuit.DefaultHash, cfg.hash)
+		}
+		// Get Verifier Key, Proving Key and the compiled circuit from the embedded bundle
+		bundle, err := DefaultBundle()
+		if err != nil {
+			return nil, err
+		}
+		g16, err = bundle.GnarkGroth16("BiddingCircuit", &c)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// check time to set up
+		var err error
+		g16, err = NewGnarkGroth16(vpKey, &c)
 		if err != nil {
 			return nil, err
 		}
 	}
+	return NewBiddingWithProver(g16, opts...)
+}
 
-	var c zkCircuit.BiddingCircuit
-	c.UserMerklePath = make([]frontend.Variable, MerkleTreeDepth+1)
-	c.UserMerkleHelper = make([]frontend.Variable, MerkleTreeDepth)
-
-	g16, err := NewGnarkGroth16(vpKey, &c)
+// NewBiddingWithProver creates a session proving with an already loaded BiddingCircuit prover, e.g. one shared by the
+// sessions of many users on a server. It returns ErrKeyMismatch when the prover isn't set up for a BiddingCircuit with
+// the hash of the options.
+func NewBiddingWithProver(g16 *GnarkGroth16, opts ...BiddingOption) (*Bidding, error) {
+	cfg, err := newBiddingConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := g16.circuit.(*zkCircuit.BiddingCircuit)
+	if !ok {
+		return nil, fmt.Errorf("%w: the prover is set up for %s, not BiddingCircuit", ErrKeyMismatch, g16.name)
+	}
+	if hash := c.Hash; hash != cfg.hash && (hash != "" || cfg.hash != zkCircuit.DefaultHash) {
+		return nil, fmt.Errorf("%w: the prover hashes with %s, not %s", ErrKeyMismatch, hash, cfg.hash)
+	}
+	mkTree, err := createMerkleTree(fakeListTesting(), cfg.hash)
+	if err != nil {
+		return nil, err
+	}
+	nullifier, err := randomNullifier()
+	if err != nil {
+		return nil, err
+	}
+	publicInputs, err := NewPublicInputSchema("BiddingCircuit")
 	if err != nil {
 		return nil, err
 	}
 
 	return &Bidding{
-		mkTree: mkTree,
-		g16:    g16,
+		mkTree:       mkTree,
+		hash:         cfg.hash,
+		g16:          g16,
+		publicInputs: publicInputs,
 		Identity: Identity{
 			Nullifier: nullifier,
 		},
@@ -103,15 +174,24 @@ func NewBidding(vpKey *VPKey) (*Bidding, error) {
 	}, nil
 }
 
+func randomNullifier() (*big.Int, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	return poseidon.HashBytes(buf)
+}
+
 func (b *Bidding) InitSession(roomId int, username string, privateCode *big.Int) error {
 	b.RoomID = roomId
 	b.Username = username
 	b.PrivateCode = privateCode
 
-	err := b.generateIdentity()
+	identity, err := b.generateIdentity(b.Identity.Nullifier)
 	if err != nil {
 		return err
 	}
+	b.Identity = identity
 	b.isReady = true
 	return nil
 }
@@ -121,42 +201,72 @@ func (b *Bidding) getUserID() *big.Int {
 	return new(big.Int).SetBytes([]byte(userInfo))
 }
 
-func (b *Bidding) getUserLeaf() []byte {
-	return zkCircuit.HashMIMC(b.getUserID().Bytes()).Bytes()
+func (b *Bidding) getUserLeaf() ([]byte, error) {
+	leaf, err := zkCircuit.HashBytes(b.hash, b.getUserID().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	// the hashes of the tree have 32 bytes
+	return leaf.FillBytes(make([]byte, fr.Bytes)), nil
 }
 
-func (b *Bidding) generateIdentity() error {
-	commitment, err := poseidon.Hash([]*big.Int{b.getUserID(), b.PrivateCode})
+// generateIdentity returns a new identity, the big.Int of an identity are never modified once returned so copies
+// (GetIdentity, assignments) stay valid when the session is renewed
+func (b *Bidding) generateIdentity(nullifier *big.Int) (Identity, error) {
+	// the hashes of the circuit, out of it
+	commitment, err := circuits.NativePoseidon([]*big.Int{b.getUserID(), b.PrivateCode})
 	if err != nil {
-		return err
+		return Identity{}, err
 	}
-	trapdoorNumber, err := poseidon.Hash([]*big.Int{commitment, b.Identity.Nullifier})
+	trapdoorNumber, err := circuits.NativePoseidon([]*big.Int{commitment, nullifier})
 	if err != nil {
-		return err
+		return Identity{}, err
 	}
-	b.Identity = Identity{
-		Nullifier:  b.Identity.Nullifier,
+	return Identity{
+		Nullifier:  nullifier,
 		Commitment: commitment,
 		Trapdoor:   trapdoorNumber,
-	}
-	return nil
+	}, nil
 }
 
 func (b *Bidding) RenewSession() error {
 	if !b.isReady {
-		return fmt.Errorf("session is not ready")
+		return ErrSessionNotReady
 	}
-	buf := make([]byte, 32)
-	if _, err := rand.Read(buf); err != nil {
+	nullifier, err := randomNullifier()
+	if err != nil {
 		return err
 	}
-	var err error
-	b.Identity.Nullifier, err = poseidon.HashBytes(buf)
+	identity, err := b.generateIdentity(nullifier)
 	if err != nil {
 		return err
 	}
+	b.Identity = identity
+	return nil
+}
+
+// SetVerifierFingerprint sets the fingerprint digest of the deployed verifier, the proofs of this session are refused
+// if it doesn't match the keys. The prover, which may be shared with other sessions, is left unchanged.
+func (b *Bidding) SetVerifierFingerprint(digest common.Hash) error {
+	b.verifierDigest = &digest
+	return b.g16.CheckVerifierFingerprint(digest)
+}
+
+// ProveOptions returns the options proving an Assignment of the session needs, e.g. with a ProverPool
+func (b *Bidding) ProveOptions() []ProveOption {
+	if b.verifierDigest == nil {
+		return nil
+	}
+	return []ProveOption{WithVerifierFingerprint(*b.verifierDigest)}
+}
 
-	return b.generateIdentity()
+// UseVerifier reads the FINGERPRINT of the verifier deployed at address, proofs are refused if it doesn't match the keys
+func (b *Bidding) UseVerifier(ctx context.Context, caller bind.ContractCaller, address common.Address) error {
+	digest, err := ReadVerifierFingerprint(ctx, caller, address)
+	if err != nil {
+		return err
+	}
+	return b.SetVerifierFingerprint(digest)
 }
 
 func (b *Bidding) GetIdentity() Identity {
@@ -168,16 +278,64 @@ func (b *Bidding) JoinRoom(roomId int) error {
 	return b.RenewSession()
 }
 
+// PublicInputSchema returns the names of the public inputs of the proofs, in the order of the verifier
+func (b *Bidding) PublicInputSchema() *PublicInputSchema {
+	return b.publicInputs
+}
+
+// GetProof proves a bid, inputs are ordered as in PublicInputSchema. Prefer GetProofInputs to read them by name.
 func (b *Bidding) GetProof(bidValue *big.Int) (*Proof, [5]*big.Int, error) {
+	proof, inputs, err := b.GetProofInputs(bidValue)
+	if err != nil {
+		return nil, [5]*big.Int{}, err
+	}
+	var publicInput [5]*big.Int
+	copy(publicInput[:], inputs.Vector())
+	return proof, publicInput, nil
+}
+
+// GetProofInputs proves a bid and returns its named public inputs
+func (b *Bidding) GetProofInputs(bidValue *big.Int) (*Proof, *PublicInputs, error) {
+	assignment, _, err := b.Assignment(bidValue)
+	if err != nil {
+		return nil, nil, err
+	}
+	inputs, err := b.publicInputs.AssignmentPublicInputs(assignment)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proofParser, _, err := b.g16.GenerateProof(context.Background(), assignment, b.ProveOptions()...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return proofParser, inputs, nil
+}
+
+// Assignment returns the witness of a bid and its public inputs without proving it. It only reads the session, the
+// witness can be proven elsewhere (e.g. by a ProverPool) while the session is renewed.
+func (b *Bidding) Assignment(bidValue *big.Int) (*zkCircuit.BiddingCircuit, [5]*big.Int, error) {
 	if !b.isReady {
-		return nil, [5]*big.Int{}, fmt.Errorf("session is not ready")
+		return nil, [5]*big.Int{}, ErrSessionNotReady
+	}
+	// the circuit only rejects a zero bid, the field would wrap around larger values
+	if bidValue == nil || bidValue.Sign() <= 0 || bidValue.Cmp(fr.Modulus()) >= 0 {
+		return nil, [5]*big.Int{}, fmt.Errorf("%w: %v", ErrBidOutOfRange, bidValue)
+	}
+	userLeaf, err := b.getUserLeaf()
+	if err != nil {
+		return nil, [5]*big.Int{}, err
+	}
+	merkleRoot, merkleProof, proofHelper, err := b.mkTree.BuilderProofHelper(userLeaf)
+	if errors.Is(err, merkleTree.ErrLeafNotFound) {
+		return nil, [5]*big.Int{}, fmt.Errorf("%w: %s in room %d", ErrNotMember, b.Username, b.RoomID)
 	}
-	merkleRoot, merkleProof, proofHelper, err := b.mkTree.BuilderProofHelper(b.getUserLeaf())
 	if err != nil {
 		return nil, [5]*big.Int{}, err
 	}
 
-	merkleAssignment := zkCircuit.BiddingCircuit{
+	identity := b.Identity
+	merkleAssignment := &zkCircuit.BiddingCircuit{
 		UserMerklePath: funk.Map(merkleProof, func(p []byte) frontend.Variable {
 			return p
 		}).([]frontend.Variable),
@@ -187,67 +345,69 @@ func (b *Bidding) GetProof(bidValue *big.Int) (*Proof, [5]*big.Int, error) {
 		UserMerkleRoot: merkleRoot,
 		BidValue:       bidValue,
 		Identity: zkCircuit.Identity{
-			Nullifier:  b.Identity.Nullifier,
-			Commitment: b.Identity.Commitment,
-			Trapdoor:   b.Identity.Trapdoor,
+			Nullifier:  identity.Nullifier,
+			Commitment: identity.Commitment,
+			Trapdoor:   identity.Trapdoor,
 		},
 		UserData: zkCircuit.UserData{
 			UserID:      b.getUserID(),
 			PrivateCode: b.PrivateCode,
 		},
+		Hash: b.hash,
 	}
 
-	proofParser, _, err := b.g16.GenerateProof(&merkleAssignment)
+	inputs, err := b.publicInputs.AssignmentPublicInputs(merkleAssignment)
 	if err != nil {
 		return nil, [5]*big.Int{}, err
 	}
 	var publicInput [5]*big.Int
-	publicInput[0] = new(big.Int).SetBytes(merkleRoot)
-	publicInput[1] = b.Identity.Nullifier
-	publicInput[2] = b.Identity.Commitment
-	publicInput[3] = b.Identity.Trapdoor
-	publicInput[4] = bidValue
+	copy(publicInput[:], inputs.Vector())
 
-	return proofParser, publicInput, nil
+	return merkleAssignment, publicInput, nil
 }
 
+// VerifyProof verifies a proof of a bid, inputs are ordered as in PublicInputSchema. Prefer VerifyProofInputs.
 func (b *Bidding) VerifyProof(proof *Proof, inputs [5]*big.Int) (bool, error) {
 	if !b.isReady {
-		return false, fmt.Errorf("session is not ready")
+		return false, ErrSessionNotReady
 	}
-
-	merklePath := make([]frontend.Variable, MerkleTreeDepth+1)
-	for i := 0; i < MerkleTreeDepth+1; i++ {
-		merklePath[i] = big.NewInt(0)
+	// reject what the Solidity verifier rejects, before gnark reduces it
+	if err := ValidateProof(proof); err != nil {
+		b.g16.verificationFailed("proof_encoding", err)
+		return false, err
 	}
-	merkleHelper := make([]frontend.Variable, MerkleTreeDepth)
-	for i := 0; i < MerkleTreeDepth; i++ {
-		merkleHelper[i] = big.NewInt(0)
+	publicInputs, err := b.publicInputs.PublicInputs(inputs[:])
+	if err != nil {
+		b.g16.verificationFailed("public_input", err)
+		return false, err
 	}
+	return b.VerifyProofInputs(proof, publicInputs)
+}
 
-	assignment := &zkCircuit.BiddingCircuit{
-		UserMerklePath:   merklePath,
-		UserMerkleHelper: merkleHelper,
-		UserMerkleRoot:   inputs[0],
-		BidValue:         inputs[4],
-		Identity: zkCircuit.Identity{
-			Nullifier:  inputs[1],
-			Commitment: inputs[2],
-			Trapdoor:   inputs[3],
-		},
-		UserData: zkCircuit.UserData{
-			UserID:      big.NewInt(0),
-			PrivateCode: big.NewInt(0),
-		},
+// VerifyProofInputs verifies a proof of a bid with its named public inputs
+func (b *Bidding) VerifyProofInputs(proof *Proof, inputs *PublicInputs) (bool, error) {
+	if !b.isReady {
+		return false, ErrSessionNotReady
+	}
+	if err := ValidateProof(proof); err != nil {
+		b.g16.verificationFailed("proof_encoding", err)
+		return false, err
+	}
+	if inputs == nil || inputs.Schema().Circuit != b.publicInputs.Circuit {
+		err := fmt.Errorf("%w: not the inputs of %s", ErrInvalidPublicInput, b.publicInputs.Circuit)
+		b.g16.verificationFailed("public_input", err)
+		return false, err
+	}
+	assignment, err := inputs.Assignment()
+	if err != nil {
+		return false, err
 	}
 
 	g16Proof := groth16.NewProof(ecc.BN254)
 	proofBuf := bytes.NewBuffer(ProofToBytes(proof))
-	fmt.Println("compare:", bytes.Equal(ProofToBytes(proof), proofBuf.Bytes()))
-
 	if _, err := g16Proof.ReadFrom(proofBuf); err != nil {
-		fmt.Println("read proof error", err)
-		return false, err
+		b.g16.verificationFailed("proof_encoding", err)
+		return false, fmt.Errorf("%w: %v", ErrProofInvalid, err)
 	}
 
 	return b.g16.VerifyProof(assignment, g16Proof)
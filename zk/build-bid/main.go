@@ -1,22 +1,39 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/frontend"
 	"github.com/thoas/go-funk"
 	"gnark-bid/zk"
 	"gnark-bid/zk/circuits"
+	"gnark-bid/zk/zktest"
 	"log"
+	"os"
 	"reflect"
 	"strings"
 )
 
-type VP struct {
-	Name string
-	Key  *zk.VPKey
-}
-
 func main() {
+	keysDir := flag.String("keys", zk.KeysDir, "directory of the key bundle")
+	solidityDir := flag.String("solidity", "solidity", "directory of the verifier contracts and the fingerprint bindings")
+	unsafeSeed := flag.String("unsafe-seed", "", "derive the keys from this seed, reproducible test keys only, never deploy them")
+	flag.Parse()
+
+	opts := []zk.SetupOption{zk.WithSolidityDir(*solidityDir)}
+	if *unsafeSeed != "" {
+		log.Println("WARNING: seeded setup, the keys are insecure")
+		opts = append(opts, zk.WithSetup(func(r1cs frontend.CompiledConstraintSystem) (groth16.ProvingKey, groth16.VerifyingKey, error) {
+			return zktest.UnsafeSeededSetup(r1cs, []byte(*unsafeSeed))
+		}))
+	}
+	for _, dir := range []string{*keysDir, *solidityDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			log.Fatal("output directory error:", err)
+		}
+	}
+
 	var cBid zk_circuit.BiddingCircuit
 	cBid.UserMerklePath = make([]frontend.Variable, zk.MerkleTreeDepth+1)
 	cBid.UserMerkleHelper = make([]frontend.Variable, zk.MerkleTreeDepth)
@@ -24,27 +41,24 @@ func main() {
 		&cBid,
 	}
 
-	keys := funk.Map(listCircuit, func(circuit frontend.Circuit) VP {
+	circuits := funk.Map(listCircuit, func(circuit frontend.Circuit) zk.BundleCircuit {
 		name := reflect.TypeOf(circuit).String()
 		structName := lastString(strings.Split(name, "."))
 		fmt.Println("circuit initializing:", structName)
-		k, _, err := zk.GenerateGroth16R1csCompiler(circuit, structName, false)
+		k, r1csCompiled, err := zk.GenerateGroth16R1csCompiler(circuit, structName, false, opts...)
 		if err != nil {
 			log.Fatal("groth16 error:", err)
 		}
-		return VP{
-			Name: structName,
-			Key:  k,
+		return zk.BundleCircuit{
+			Name:    structName,
+			Version: zk.CircuitVersion(circuit),
+			R1CS:    r1csCompiled,
+			Key:     k,
 		}
-	}).([]VP)
-
-	mapKeys := make(map[string]*zk.VPKey)
-	for _, k := range keys {
-		mapKeys[k.Name] = k.Key
-	}
+	}).([]zk.BundleCircuit)
 
-	if err := zk.WriteTemplateKey(mapKeys); err != nil {
-		log.Fatal("write template error:", err)
+	if err := zk.WriteBundle(*keysDir, circuits...); err != nil {
+		log.Fatal("write bundle error:", err)
 	}
 }
 
@@ -2,11 +2,10 @@ package zk
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"os"
-	"text/template"
+	"path/filepath"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/groth16"
@@ -14,13 +13,40 @@ import (
 	"github.com/consensys/gnark/frontend/cs/r1cs"
 )
 
-func GenerateGroth16R1csCompiler(c frontend.Circuit, name string, isWriteFileKey bool) (*VPKey, frontend.CompiledConstraintSystem, error) {
+// SetupOption configures GenerateGroth16R1csCompiler
+type SetupOption func(*setupConfig)
+
+type setupConfig struct {
+	setup       func(frontend.CompiledConstraintSystem) (groth16.ProvingKey, groth16.VerifyingKey, error)
+	solidityDir string
+}
+
+// WithSetup runs setup instead of groth16.Setup, e.g. zktest.UnsafeSeededSetup for reproducible test keys
+func WithSetup(setup func(frontend.CompiledConstraintSystem) (groth16.ProvingKey, groth16.VerifyingKey, error)) SetupOption {
+	return func(cfg *setupConfig) {
+		cfg.setup = setup
+	}
+}
+
+// WithSolidityDir writes the verifier contract and the fingerprint bindings to dir instead of solidity
+func WithSolidityDir(dir string) SetupOption {
+	return func(cfg *setupConfig) {
+		cfg.solidityDir = dir
+	}
+}
+
+func GenerateGroth16R1csCompiler(c frontend.Circuit, name string, isWriteFileKey bool, opts ...SetupOption) (*VPKey, frontend.CompiledConstraintSystem, error) {
+	cfg := setupConfig{setup: groth16.Setup, solidityDir: "solidity"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	r1csCompiled, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, c)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	pk, vk, err := groth16.Setup(r1csCompiled)
+	pk, vk, err := cfg.setup(r1csCompiled)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -28,7 +54,7 @@ func GenerateGroth16R1csCompiler(c frontend.Circuit, name string, isWriteFileKey
 	if isWriteFileKey {
 
 		{
-			f, err := os.Create("solidity/r1cs.compiled.zk")
+			f, err := os.Create(filepath.Join(cfg.solidityDir, "r1cs.compiled.zk"))
 			if err != nil {
 				return nil, nil, err
 			}
@@ -38,7 +64,7 @@ func GenerateGroth16R1csCompiler(c frontend.Circuit, name string, isWriteFileKey
 		}
 
 		{
-			f, err := os.Create("solidity/zk.g16.vk")
+			f, err := os.Create(filepath.Join(cfg.solidityDir, "zk.g16.vk"))
 			if err != nil {
 				return nil, nil, err
 			}
@@ -48,7 +74,7 @@ func GenerateGroth16R1csCompiler(c frontend.Circuit, name string, isWriteFileKey
 		}
 
 		{
-			f, err := os.Create("solidity/zk.g16.pk")
+			f, err := os.Create(filepath.Join(cfg.solidityDir, "zk.g16.pk"))
 			if err != nil {
 				return nil, nil, err
 			}
@@ -58,20 +84,30 @@ func GenerateGroth16R1csCompiler(c frontend.Circuit, name string, isWriteFileKey
 		}
 	}
 
-	{
-		f, err := os.Create(fmt.Sprintf("solidity/Contract_%s.sol", name))
-		if err != nil {
-			return nil, nil, err
-		}
-		err = vk.ExportSolidity(f)
-		if err != nil {
-			return nil, nil, err
-		}
+	fp, err := NewFingerprint(name, CircuitVersion(c), r1csCompiled, vk)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := WriteSolidityVerifier(cfg.solidityDir, fp, vk); err != nil {
+		return nil, nil, err
 	}
 	vpKey, err := CreateVPKey(pk, vk)
 	return vpKey, r1csCompiled, err
 }
 
+// WriteSolidityVerifier writes the verifier contract of a circuit and the Go file declaring its fingerprint to dir
+func WriteSolidityVerifier(dir string, fp Fingerprint, vk groth16.VerifyingKey) error {
+	f, err := os.Create(filepath.Join(dir, fmt.Sprintf("Contract_%s.sol", fp.Circuit)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := fp.ExportSolidity(f, vk); err != nil {
+		return err
+	}
+	return WriteFingerprintBindings(filepath.Join(dir, fmt.Sprintf("solidity_Fingerprint_%s.go", fp.Circuit)), filepath.Base(dir), fp)
+}
+
 func CreateVPKey(pk groth16.ProvingKey, vk groth16.VerifyingKey) (*VPKey, error) {
 	bufVk := new(bytes.Buffer)
 	bufPk := new(bytes.Buffer)
@@ -87,6 +123,8 @@ func CreateVPKey(pk groth16.ProvingKey, vk groth16.VerifyingKey) (*VPKey, error)
 	return &VPKey{
 		ProvingKey:   common.Bytes2Hex(bufPk.Bytes()),
 		VerifyingKey: common.Bytes2Hex(bufVk.Bytes()),
+		VK:           vk,
+		PK:           pk,
 	}, nil
 }
 
@@ -100,26 +138,3 @@ func WriteJsonFile(jsonBytes []byte, fileName string) error {
 	_, err = bufJson.WriteTo(f)
 	return err
 }
-
-func WriteTemplateKey(data map[string]*VPKey) error {
-	jsonBytes, _ := json.Marshal(data)
-	return GenerateTemplateKey(jsonBytes, "zk/parse_vp_key.go")
-}
-
-func GenerateTemplateKey(jsonBytes []byte, fileName string) error {
-	getwd, err := os.Getwd()
-	if err != nil {
-		return err
-	}
-	tmpl := template.Must(template.ParseFiles(fmt.Sprintf("%s/zk/build/parse_vp_key-template.go.temp", getwd)))
-	var tpl bytes.Buffer
-	if err := tmpl.Execute(&tpl, string(jsonBytes)); err != nil {
-		return err
-	}
-	f, err := os.Create(fileName)
-	if err != nil {
-		return err
-	}
-	_, err = tpl.WriteTo(f)
-	return err
-}
@@ -0,0 +1,328 @@
+package zk
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// BundleVersion is the version of the on-disk artifact bundle format written by WriteBundle
+const BundleVersion = 1
+
+// BundleManifestFile is the name of the manifest at the root of a bundle
+const BundleManifestFile = "manifest.json"
+
+// artifact kinds stored for each circuit of a bundle
+const (
+	ArtifactR1CS     = "r1cs"
+	ArtifactPK       = "pk"
+	ArtifactVK       = "vk"
+	ArtifactSolidity = "solidity"
+)
+
+var artifactFileNames = map[string]string{
+	ArtifactR1CS:     "circuit.r1cs",
+	ArtifactPK:       "circuit.pk",
+	ArtifactVK:       "circuit.vk",
+	ArtifactSolidity: "Verifier.sol",
+}
+
+// BundleManifest describes the content of a bundle: one entry per circuit
+type BundleManifest struct {
+	Version  int                         `json:"version"`
+	Circuits map[string]*CircuitManifest `json:"circuits"`
+}
+
+// CircuitManifest holds the metadata of a circuit and the files of its artifacts
+type CircuitManifest struct {
+	Name                string                  `json:"name"`
+	Curve               string                  `json:"curve"`
+	Backend             string                  `json:"backend"`
+	NbConstraints       int                     `json:"nbConstraints"`
+	NbPublicVariables   int                     `json:"nbPublicVariables"`
+	NbSecretVariables   int                     `json:"nbSecretVariables"`
+	NbInternalVariables int                     `json:"nbInternalVariables"`
+	Fingerprint         *Fingerprint            `json:"fingerprint,omitempty"`
+	Files               map[string]ArtifactFile `json:"files"`
+}
+
+// ArtifactFile is a file of a bundle, path is relative to the bundle root
+type ArtifactFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// BundleCircuit groups the artifacts of one circuit written to a bundle
+type BundleCircuit struct {
+	Name    string
+	Version int
+	R1CS    frontend.CompiledConstraintSystem
+	Key     *VPKey
+}
+
+// Bundle is a loaded artifact bundle, keys are parsed on first use and cached
+type Bundle struct {
+	Manifest BundleManifest
+
+	fsys fs.FS
+	mu   sync.Mutex
+	keys map[string]*VPKey
+}
+
+// LoadBundle reads the manifest of a bundle stored in fsys
+func LoadBundle(fsys fs.FS) (*Bundle, error) {
+	data, err := fs.ReadFile(fsys, BundleManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	var manifest BundleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Version != BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d, want %d", manifest.Version, BundleVersion)
+	}
+
+	return &Bundle{
+		Manifest: manifest,
+		fsys:     fsys,
+		keys:     make(map[string]*VPKey),
+	}, nil
+}
+
+// LoadBundleDir reads a bundle written to dir by WriteBundle
+func LoadBundleDir(dir string) (*Bundle, error) {
+	return LoadBundle(os.DirFS(dir))
+}
+
+// Circuits returns the sorted names of the circuits in the bundle
+func (b *Bundle) Circuits() []string {
+	names := make([]string, 0, len(b.Manifest.Circuits))
+	for name := range b.Manifest.Circuits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Circuit returns the manifest entry of a circuit
+func (b *Bundle) Circuit(name string) (*CircuitManifest, error) {
+	c := b.Manifest.Circuits[name]
+	if c == nil {
+		return nil, fmt.Errorf("no key for %s", name)
+	}
+	return c, nil
+}
+
+// ReadArtifact returns the content of an artifact after checking its checksum
+func (b *Bundle) ReadArtifact(name, kind string) ([]byte, error) {
+	c, err := b.Circuit(name)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := c.Files[kind]
+	if !ok {
+		return nil, fmt.Errorf("no %s artifact for %s", kind, name)
+	}
+	data, err := fs.ReadFile(b.fsys, f.Path)
+	if err != nil {
+		return nil, err
+	}
+	if sum := checksum(data); sum != f.SHA256 {
+		return nil, fmt.Errorf("checksum mismatch for %s: got %s, want %s", f.Path, sum, f.SHA256)
+	}
+	return data, nil
+}
+
+// VPKey returns the proving and verifying keys of a circuit
+func (b *Bundle) VPKey(name string) (*VPKey, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if vp, ok := b.keys[name]; ok {
+		return vp, nil
+	}
+
+	pkBytes, err := b.ReadArtifact(name, ArtifactPK)
+	if err != nil {
+		return nil, err
+	}
+	vkBytes, err := b.ReadArtifact(name, ArtifactVK)
+	if err != nil {
+		return nil, err
+	}
+
+	vp := &VPKey{
+		PK: groth16.NewProvingKey(ecc.BN254),
+		VK: groth16.NewVerifyingKey(ecc.BN254),
+	}
+	// checksums are verified above, so the subgroup checks can be skipped for the (large) proving key
+	if _, err := vp.PK.UnsafeReadFrom(bytes.NewReader(pkBytes)); err != nil {
+		return nil, err
+	}
+	if _, err := vp.VK.ReadFrom(bytes.NewReader(vkBytes)); err != nil {
+		return nil, err
+	}
+
+	b.keys[name] = vp
+	return vp, nil
+}
+
+// ConstraintSystem returns the compiled R1CS of a circuit
+func (b *Bundle) ConstraintSystem(name string) (frontend.CompiledConstraintSystem, error) {
+	data, err := b.ReadArtifact(name, ArtifactR1CS)
+	if err != nil {
+		return nil, err
+	}
+	r1csCompiled := groth16.NewCS(ecc.BN254)
+	if _, err := r1csCompiled.ReadFrom(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return r1csCompiled, nil
+}
+
+// GnarkGroth16 creates a prover for a circuit from its keys and compiled R1CS, without compiling circuit
+func (b *Bundle) GnarkGroth16(name string, circuit frontend.Circuit) (*GnarkGroth16, error) {
+	c, err := b.Circuit(name)
+	if err != nil {
+		return nil, err
+	}
+	vpKey, err := b.VPKey(name)
+	if err != nil {
+		return nil, err
+	}
+	r1csCompiled, err := b.ConstraintSystem(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, public := r1csCompiled.GetNbVariables(); public != c.NbPublicVariables || r1csCompiled.GetNbConstraints() != c.NbConstraints {
+		return nil, fmt.Errorf("r1cs of %s does not match its manifest", name)
+	}
+	g16, err := NewGnarkGroth16WithCS(vpKey, r1csCompiled, circuit)
+	if err != nil {
+		return nil, err
+	}
+	if c.Fingerprint != nil {
+		fp, err := g16.Fingerprint()
+		if err != nil {
+			return nil, err
+		}
+		if err := fp.Check(c.Fingerprint.Digest()); err != nil {
+			return nil, err
+		}
+	}
+	return g16, nil
+}
+
+// Fingerprint returns the fingerprint of a circuit recorded in the manifest
+func (b *Bundle) Fingerprint(name string) (Fingerprint, error) {
+	c, err := b.Circuit(name)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	if c.Fingerprint == nil {
+		return Fingerprint{}, fmt.Errorf("no fingerprint for %s", name)
+	}
+	return *c.Fingerprint, nil
+}
+
+// Solidity returns the Solidity verifier exported from the verifying key of a circuit
+func (b *Bundle) Solidity(name string) ([]byte, error) {
+	return b.ReadArtifact(name, ArtifactSolidity)
+}
+
+// WriteBundle writes the artifacts of circuits to dir. Circuits already present in the manifest of dir
+// and not given here are kept.
+func WriteBundle(dir string, circuits ...BundleCircuit) error {
+	manifest := BundleManifest{
+		Version:  BundleVersion,
+		Circuits: make(map[string]*CircuitManifest),
+	}
+	if existing, err := LoadBundleDir(dir); err == nil {
+		manifest.Circuits = existing.Manifest.Circuits
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	for _, c := range circuits {
+		if c.R1CS == nil || c.Key == nil || c.Key.PK == nil || c.Key.VK == nil {
+			return fmt.Errorf("incomplete artifacts for %s", c.Name)
+		}
+		fp, err := NewFingerprint(c.Name, c.Version, c.R1CS, c.Key.VK)
+		if err != nil {
+			return err
+		}
+		internal, secret, public := c.R1CS.GetNbVariables()
+		cm := &CircuitManifest{
+			Name:                c.Name,
+			Curve:               c.R1CS.CurveID().String(),
+			Backend:             "groth16",
+			NbConstraints:       c.R1CS.GetNbConstraints(),
+			NbPublicVariables:   public,
+			NbSecretVariables:   secret,
+			NbInternalVariables: internal,
+			Fingerprint:         &fp,
+			Files:               make(map[string]ArtifactFile),
+		}
+
+		artifacts := map[string]func(w io.Writer) error{
+			ArtifactR1CS: func(w io.Writer) error {
+				_, err := c.R1CS.WriteTo(w)
+				return err
+			},
+			ArtifactPK: func(w io.Writer) error {
+				_, err := c.Key.PK.WriteRawTo(w)
+				return err
+			},
+			ArtifactVK: func(w io.Writer) error {
+				_, err := c.Key.VK.WriteRawTo(w)
+				return err
+			},
+			ArtifactSolidity: func(w io.Writer) error {
+				return fp.ExportSolidity(w, c.Key.VK)
+			},
+		}
+		for kind, write := range artifacts {
+			var buf bytes.Buffer
+			if err := write(&buf); err != nil {
+				return err
+			}
+			p := path.Join(c.Name, artifactFileNames[kind])
+			if err := os.MkdirAll(filepath.Join(dir, c.Name), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(p)), buf.Bytes(), 0o644); err != nil {
+				return err
+			}
+			cm.Files[kind] = ArtifactFile{Path: p, SHA256: checksum(buf.Bytes())}
+		}
+		manifest.Circuits[c.Name] = cm
+	}
+
+	jsonBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return WriteJsonFile(append(jsonBytes, '\n'), filepath.Join(dir, BundleManifestFile))
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,120 @@
+package zk_test
+
+import (
+	"context"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"gnark-bid/zk"
+	"gnark-bid/zk/circuits"
+	"gnark-bid/zk/zktest"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	assert := test.NewAssert(t)
+	dir := t.TempDir()
+
+	var circuit zk_circuit.PrivateValueCircuit
+	r1csCompiled, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit)
+	assert.NoError(err, "compilation failed")
+	pk, vk, err := zktest.UnsafeSeededSetup(r1csCompiled, []byte(t.Name()))
+	assert.NoError(err, "setup failed")
+	vpKey, err := zk.CreateVPKey(pk, vk)
+	assert.NoError(err)
+	assert.NoError(zk.WriteBundle(dir, zk.BundleCircuit{Name: "PrivateValueCircuit", R1CS: r1csCompiled, Key: vpKey}))
+
+	bundle, err := zk.LoadBundleDir(dir)
+	assert.NoError(err, "loading bundle failed")
+	assert.Equal([]string{"PrivateValueCircuit"}, bundle.Circuits())
+
+	c, err := bundle.Circuit("PrivateValueCircuit")
+	assert.NoError(err)
+	assert.Equal(r1csCompiled.GetNbConstraints(), c.NbConstraints)
+	assert.Equal(2, c.NbPublicVariables)
+
+	loadedCS, err := bundle.ConstraintSystem("PrivateValueCircuit")
+	assert.NoError(err, "loading r1cs failed")
+	assert.Equal(r1csCompiled.GetNbConstraints(), loadedCS.GetNbConstraints())
+
+	loadedKey, err := bundle.VPKey("PrivateValueCircuit")
+	assert.NoError(err, "loading keys failed")
+	assert.False(loadedKey.VK.IsDifferent(vpKey.VK), "verifying key should round trip")
+
+	g16, err := bundle.GnarkGroth16("PrivateValueCircuit", &circuit)
+	assert.NoError(err, "loading prover from bundle failed")
+	assignment := zk_circuit.PrivateValueCircuit{
+		PrivateValue: 42,
+		Hash:         zk_circuit.HashMIMC(big.NewInt(42).Bytes()),
+	}
+	_, _, err = g16.GenerateProof(context.Background(), &assignment)
+	assert.NoError(err, "proving with bundle keys failed")
+
+	// the keys and the r1cs of another circuit don't match
+	var merkleCircuit zk_circuit.MerkleCircuit
+	merkleCircuit.Path = make([]frontend.Variable, 3)
+	merkleCircuit.Helper = make([]frontend.Variable, 2)
+	merkleCS, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &merkleCircuit)
+	assert.NoError(err, "compilation failed")
+	assert.Error(zk.CheckConstraintSystem(merkleCS, loadedKey.PK, loadedKey.VK, nil), "r1cs should not match the keys")
+	assert.Error(zk.CheckConstraintSystem(loadedCS, loadedKey.PK, loadedKey.VK, &merkleCircuit), "r1cs should not match the circuit")
+	assert.NoError(zk.CheckConstraintSystem(loadedCS, loadedKey.PK, loadedKey.VK, &circuit))
+
+	_, err = bundle.VPKey("BiddingCircuit")
+	assert.Error(err, "missing circuit should fail")
+
+	fp, err := bundle.Fingerprint("PrivateValueCircuit")
+	assert.NoError(err)
+	loadedFp, err := g16.Fingerprint()
+	assert.NoError(err)
+	assert.Equal(fp, loadedFp, "fingerprint should match the manifest")
+	assert.NoError(g16.CheckVerifierFingerprint(fp.Digest()))
+	assert.ErrorIs(g16.CheckVerifierFingerprint(common.Hash{}), zk.ErrFingerprintMismatch)
+	_, _, err = g16.GenerateProof(context.Background(), &assignment, zk.WithVerifierFingerprint(common.Hash{}))
+	assert.ErrorIs(err, zk.ErrFingerprintMismatch, "proving for another verifier should fail")
+	_, _, err = g16.GenerateProof(context.Background(), &assignment, zk.WithVerifierFingerprint(fp.Digest()))
+	assert.NoError(err, "the check should not outlive the proof")
+
+	// keys from another setup
+	otherPK, _, err := groth16.Setup(r1csCompiled)
+	assert.NoError(err, "setup failed")
+	_, err = zk.NewGnarkGroth16(&zk.VPKey{PK: otherPK, VK: vk}, &circuit)
+	assert.ErrorIs(err, zk.ErrFingerprintMismatch, "keys from different setups should be refused")
+
+	// tampered artifacts are rejected
+	assert.NoError(os.WriteFile(filepath.Join(dir, "PrivateValueCircuit", "circuit.vk"), []byte{1, 2, 3}, 0o644))
+	tampered, err := zk.LoadBundleDir(dir)
+	assert.NoError(err)
+	_, err = tampered.VPKey("PrivateValueCircuit")
+	assert.Error(err, "checksum mismatch should fail")
+}
+
+func TestEmbeddedBundle(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	vpKey, err := zk.GetVPKey("BiddingCircuit")
+	assert.NoError(err, "loading embedded keys failed")
+	assert.Equal(6, vpKey.VK.NbPublicWitness()+1)
+
+	again, err := zk.GetVPKey("BiddingCircuit")
+	assert.NoError(err)
+	assert.True(vpKey == again, "keys should be parsed once")
+
+	// the circuit compiled again, with other debug info than the bundled r1cs, has the fingerprint of the bundle
+	bundle, err := zk.DefaultBundle()
+	assert.NoError(err)
+	fp, err := bundle.Fingerprint("BiddingCircuit")
+	assert.NoError(err)
+	var circuit zk_circuit.BiddingCircuit
+	circuit.UserMerklePath = make([]frontend.Variable, zk.MerkleTreeDepth+1)
+	circuit.UserMerkleHelper = make([]frontend.Variable, zk.MerkleTreeDepth)
+	g16, err := zk.NewGnarkGroth16(vpKey, &circuit)
+	assert.NoError(err)
+	assert.NoError(g16.CheckVerifierFingerprint(fp.Digest()))
+}
@@ -0,0 +1,95 @@
+package zk
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+const verifyProofMethod = "verifyProof"
+
+// Calldata is an ABI encoded call to the verifyProof method of a generated Groth16 verifier.
+type Calldata struct {
+	// Data is the full calldata, including the 4 bytes method selector
+	Data []byte
+
+	A     [2]*big.Int
+	B     [2][2]*big.Int
+	C     [2]*big.Int
+	Input []*big.Int
+}
+
+// GenerateCalldata packs a proof and its public inputs into calldata for the verifyProof method of verifierABI,
+// the ABI json of a verifier exported by groth16.VerifyingKey.ExportSolidity.
+// A missing, negative or out of range proof coordinate gives ErrProofInvalid, a missing, negative or out of range
+// input and a wrong number of inputs give ErrInvalidPublicInput.
+func GenerateCalldata(verifierABI string, proof *Proof, inputs []*big.Int) (*Calldata, error) {
+	if err := ValidateProof(proof); err != nil {
+		return nil, err
+	}
+	if err := ValidatePublicInputs(inputs); err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(strings.NewReader(verifierABI))
+	if err != nil {
+		return nil, err
+	}
+	method, ok := parsed.Methods[verifyProofMethod]
+	if !ok {
+		return nil, fmt.Errorf("abi has no %s method", verifyProofMethod)
+	}
+	if len(method.Inputs) != 4 {
+		return nil, fmt.Errorf("%s expects %d arguments, want 4", verifyProofMethod, len(method.Inputs))
+	}
+
+	// the size of the input array depends on the circuit, so it is built from the abi type
+	inputType := method.Inputs[3].Type
+	if inputType.T != abi.ArrayTy || inputType.Size != len(inputs) {
+		return nil, fmt.Errorf("%w: %s expects %s as input, got %d values", ErrInvalidPublicInput, verifyProofMethod, inputType.String(), len(inputs))
+	}
+	inputArray := reflect.New(inputType.GetType()).Elem()
+	for i, v := range inputs {
+		inputArray.Index(i).Set(reflect.ValueOf(v))
+	}
+
+	data, err := parsed.Pack(verifyProofMethod, proof.A, proof.B, proof.C, inputArray.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Calldata{
+		Data:  data,
+		A:     proof.A,
+		B:     proof.B,
+		C:     proof.C,
+		Input: inputs,
+	}, nil
+}
+
+// Hex returns the 0x prefixed calldata, e.g. for `cast call <verifier> <calldata>` or eth_call.
+func (c *Calldata) Hex() string {
+	return hexutil.Encode(c.Data)
+}
+
+// String returns the verifyProof arguments in the format accepted by Remix and `cast call <verifier> "verifyProof(...)"`:
+// ["a0","a1"],[["b00","b01"],["b10","b11"]],["c0","c1"],["input0",...]
+func (c *Calldata) String() string {
+	b := [2]string{formatHexArray(c.B[0][:]), formatHexArray(c.B[1][:])}
+	return strings.Join([]string{
+		formatHexArray(c.A[:]),
+		"[" + strings.Join(b[:], ",") + "]",
+		formatHexArray(c.C[:]),
+		formatHexArray(c.Input),
+	}, ",")
+}
+
+func formatHexArray(values []*big.Int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = fmt.Sprintf("%q", hexutil.EncodeBig(v))
+	}
+	return "[" + strings.Join(s, ",") + "]"
+}
@@ -0,0 +1,478 @@
+package zk
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"io"
+	"math/big"
+	"os"
+	"reflect"
+)
+
+// CeremonyVersion is the version of the ceremony transcript format
+const CeremonyVersion = 2
+
+const ceremonyDST = "GNARK-BID-PHASE2-V1_BN254G2_XMD:SHA-256_SVDW_RO_"
+
+// ErrInvalidContribution is returned when a ceremony transcript doesn't verify
+var ErrInvalidContribution = errors.New("invalid ceremony contribution")
+
+// Ceremony is the transcript of a multi-party phase-2 Groth16 setup (Bowe, Gabizon, Miers https://eprint.iacr.org/2017/1050).
+//
+// Each participant multiplies [δ] by a secret x and divides the δ-dependent parts of the proving key by x,
+// then forgets x: δ is unknown as long as one participant is honest.
+//
+// The initial keys are derived from the τ, α and β of a PowersOfTau transcript, with γ = δ = 1: no secret is known to
+// whoever runs NewCeremony, and anyone holding the phase-1 transcript can check it (Verify).
+type Ceremony struct {
+	Version       int                    `json:"version"`
+	Circuit       string                 `json:"circuit"`
+	R1CSHash      string                 `json:"r1csHash"`
+	PowersOfTau   string                 `json:"powersOfTau"` // PowersOfTau.Hash of the phase 1
+	Initial       CeremonyParameters     `json:"initial"`
+	Contributions []CeremonyContribution `json:"contributions"`
+	Current       CeremonyParameters     `json:"current"`
+}
+
+// CeremonyParameters are raw serialized groth16 keys
+type CeremonyParameters struct {
+	ProvingKey   []byte `json:"provingKey"`
+	VerifyingKey []byte `json:"verifyingKey"`
+}
+
+// CeremonyContribution is the public part of a contribution with its proof of knowledge of x:
+// r = hashToG2(challenge ∥ s ∥ s·x), and the verifier checks e(s, r·x) = e(s·x, r) and e(δ, r) = e(δprev, r·x)
+type CeremonyContribution struct {
+	Name      string `json:"name"`
+	Challenge []byte `json:"challenge"`
+	Delta     []byte `json:"delta"` // [δ]1 after the contribution
+	S         []byte `json:"s"`     // [s]1
+	SX        []byte `json:"sx"`    // [s·x]1
+	RX        []byte `json:"rx"`    // [r·x]2
+}
+
+// NewCeremony starts the phase 2 of a circuit from a verified phase-1 transcript with at least one contribution
+func NewCeremony(name string, r1cs frontend.CompiledConstraintSystem, phase1 *PowersOfTau) (*Ceremony, error) {
+	if err := phase1.Verify(); err != nil {
+		return nil, err
+	}
+	if len(phase1.Contributions) == 0 {
+		return nil, fmt.Errorf("%w: the powers of tau have no contribution", ErrInvalidContribution)
+	}
+	pk, vk, err := phase1.groth16Keys(r1cs)
+	if err != nil {
+		return nil, err
+	}
+	params, err := newCeremonyParameters(pk, vk)
+	if err != nil {
+		return nil, err
+	}
+	r1csHash, err := hashConstraintSystem(r1cs)
+	if err != nil {
+		return nil, err
+	}
+	return &Ceremony{
+		Version:     CeremonyVersion,
+		Circuit:     name,
+		R1CSHash:    r1csHash,
+		PowersOfTau: phase1.Hash(),
+		Initial:     params,
+		Current:     params,
+	}, nil
+}
+
+// ReadCeremony reads a transcript file
+func ReadCeremony(fileName string) (*Ceremony, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var c Ceremony
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if c.Version != CeremonyVersion {
+		return nil, fmt.Errorf("unsupported ceremony version %d, want %d", c.Version, CeremonyVersion)
+	}
+	return &c, nil
+}
+
+// WriteFile writes the transcript to a file, to be passed to the next participant
+func (c *Ceremony) WriteFile(fileName string) error {
+	jsonBytes, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, jsonBytes, 0o644)
+}
+
+// Contribute adds the randomness read from random (crypto/rand.Reader if nil) to the current keys
+func (c *Ceremony) Contribute(name string, random io.Reader) error {
+	if random == nil {
+		random = rand.Reader
+	}
+	pk, vk, err := c.Current.keys(false)
+	if err != nil {
+		return err
+	}
+	_pk, err := provingKeyBN254(pk)
+	if err != nil {
+		return err
+	}
+	_vk, err := verifyingKeyBN254(vk)
+	if err != nil {
+		return err
+	}
+
+	x, err := randomScalar(random)
+	if err != nil {
+		return err
+	}
+	s, err := randomScalar(random)
+	if err != nil {
+		return err
+	}
+	var xInv big.Int
+	xInv.ModInverse(x, fr.Modulus())
+
+	// proof of knowledge of x
+	_, _, g1, _ := bn254.Generators()
+	var sG1, sxG1 bn254.G1Affine
+	sG1.ScalarMultiplication(&g1, s)
+	sxG1.ScalarMultiplication(&sG1, x)
+	challenge := c.challenge(len(c.Contributions))
+	r, err := ceremonyR(challenge, &sG1, &sxG1)
+	if err != nil {
+		return err
+	}
+	var rxG2 bn254.G2Affine
+	rxG2.ScalarMultiplication(&r, x)
+
+	// δ ← δ·x, K ← K/x, Z ← Z/x
+	_pk.G1Delta.ScalarMultiplication(_pk.G1Delta, x)
+	_pk.G2Delta.ScalarMultiplication(_pk.G2Delta, x)
+	*_vk.G1Delta = *_pk.G1Delta
+	*_vk.G2Delta = *_pk.G2Delta
+	for _, points := range []*[]bn254.G1Affine{_pk.G1K, _pk.G1Z} {
+		for i := range *points {
+			(*points)[i].ScalarMultiplication(&(*points)[i], &xInv)
+		}
+	}
+
+	params, err := newCeremonyParameters(pk, vk)
+	if err != nil {
+		return err
+	}
+	delta := _pk.G1Delta.Bytes()
+	sBytes, sxBytes, rxBytes := sG1.Bytes(), sxG1.Bytes(), rxG2.Bytes()
+	c.Contributions = append(c.Contributions, CeremonyContribution{
+		Name:      name,
+		Challenge: challenge,
+		Delta:     delta[:],
+		S:         sBytes[:],
+		SX:        sxBytes[:],
+		RX:        rxBytes[:],
+	})
+	c.Current = params
+
+	// x and s are not stored anywhere else
+	x.SetUint64(0)
+	s.SetUint64(0)
+	xInv.SetUint64(0)
+	return nil
+}
+
+// Verify checks the whole transcript: each proof of knowledge, the chain of [δ]1 and that the current keys only differ
+// from the initial ones by the accumulated δ. r1cs and phase1 are optional: r1cs must be the circuit of the ceremony,
+// and with both the initial keys must be the ones derived from the phase-1 transcript.
+func (c *Ceremony) Verify(r1cs frontend.CompiledConstraintSystem, phase1 *PowersOfTau) error {
+	initialPK, initialVK, err := c.Initial.keys(true)
+	if err != nil {
+		return err
+	}
+	currentPK, currentVK, err := c.Current.keys(true)
+	if err != nil {
+		return err
+	}
+	if r1cs != nil {
+		r1csHash, err := hashConstraintSystem(r1cs)
+		if err != nil {
+			return err
+		}
+		if r1csHash != c.R1CSHash {
+			return fmt.Errorf("r1cs hash %s, ceremony is for %s", r1csHash, c.R1CSHash)
+		}
+		if err := CheckConstraintSystem(r1cs, currentPK, currentVK, nil); err != nil {
+			return err
+		}
+	}
+	if phase1 != nil {
+		if err := c.verifyInitial(r1cs, phase1); err != nil {
+			return err
+		}
+	}
+	if err := checkKeyPair(initialPK, initialVK); err != nil {
+		return err
+	}
+	if err := checkKeyPair(currentPK, currentVK); err != nil {
+		return err
+	}
+
+	initial, err := provingKeyBN254(initialPK)
+	if err != nil {
+		return err
+	}
+	current, err := provingKeyBN254(currentPK)
+	if err != nil {
+		return err
+	}
+
+	// chain of contributions
+	prevDelta := *initial.G1Delta
+	for i, contribution := range c.Contributions {
+		if !bytes.Equal(contribution.Challenge, c.challenge(i)) {
+			return fmt.Errorf("%w %d (%s): wrong challenge", ErrInvalidContribution, i, contribution.Name)
+		}
+		var delta, s, sx bn254.G1Affine
+		var rx bn254.G2Affine
+		for _, p := range []struct {
+			set func([]byte) (int, error)
+			b   []byte
+		}{{delta.SetBytes, contribution.Delta}, {s.SetBytes, contribution.S}, {sx.SetBytes, contribution.SX}, {rx.SetBytes, contribution.RX}} {
+			if _, err := p.set(p.b); err != nil {
+				return fmt.Errorf("%w %d (%s): %v", ErrInvalidContribution, i, contribution.Name, err)
+			}
+		}
+		if delta.IsInfinity() || s.IsInfinity() || sx.IsInfinity() || rx.IsInfinity() {
+			return fmt.Errorf("%w %d (%s): point at infinity", ErrInvalidContribution, i, contribution.Name)
+		}
+		r, err := ceremonyR(contribution.Challenge, &s, &sx)
+		if err != nil {
+			return err
+		}
+		var sxNeg, prevDeltaNeg bn254.G1Affine
+		sxNeg.Neg(&sx)
+		prevDeltaNeg.Neg(&prevDelta)
+		if ok, err := bn254.PairingCheck([]bn254.G1Affine{s, sxNeg}, []bn254.G2Affine{rx, r}); err != nil || !ok {
+			return fmt.Errorf("%w %d (%s): invalid proof of knowledge", ErrInvalidContribution, i, contribution.Name)
+		}
+		if ok, err := bn254.PairingCheck([]bn254.G1Affine{delta, prevDeltaNeg}, []bn254.G2Affine{r, rx}); err != nil || !ok {
+			return fmt.Errorf("%w %d (%s): [δ]1 is not the previous one multiplied by x", ErrInvalidContribution, i, contribution.Name)
+		}
+		prevDelta = delta
+	}
+
+	// current keys
+	if !current.G1Delta.Equal(&prevDelta) {
+		return fmt.Errorf("%w: current [δ]1 is not the one of the last contribution", ErrInvalidContribution)
+	}
+	_, _, g1, g2 := bn254.Generators()
+	var g1Neg bn254.G1Affine
+	g1Neg.Neg(&g1)
+	if ok, err := bn254.PairingCheck([]bn254.G1Affine{*current.G1Delta, g1Neg}, []bn254.G2Affine{g2, *current.G2Delta}); err != nil || !ok {
+		return fmt.Errorf("%w: current [δ]1 and [δ]2 differ", ErrInvalidContribution)
+	}
+	if err := checkUnchangedParameters(initialPK, initialVK, currentPK, currentVK); err != nil {
+		return err
+	}
+
+	// K and Z are divided by the same accumulated x: e(Σρ·K', δ'2) = e(Σρ·K, δ2)
+	if len(*current.G1K) != len(*initial.G1K) || len(*current.G1Z) != len(*initial.G1Z) {
+		return fmt.Errorf("%w: proving key dimensions changed", ErrInvalidContribution)
+	}
+	points := append(append([]bn254.G1Affine{}, *initial.G1K...), *initial.G1Z...)
+	currentPoints := append(append([]bn254.G1Affine{}, *current.G1K...), *current.G1Z...)
+	rho := make([]fr.Element, len(points))
+	for i := range rho {
+		if _, err := rho[i].SetRandom(); err != nil {
+			return err
+		}
+	}
+	var lc, currentLC bn254.G1Affine
+	if _, err := lc.MultiExp(points, rho, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	if _, err := currentLC.MultiExp(currentPoints, rho, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	lc.Neg(&lc)
+	if ok, err := bn254.PairingCheck([]bn254.G1Affine{currentLC, lc}, []bn254.G2Affine{*current.G2Delta, *initial.G2Delta}); err != nil || !ok {
+		return fmt.Errorf("%w: [K]1, [Z]1 are not consistent with [δ]2", ErrInvalidContribution)
+	}
+
+	return nil
+}
+
+// Finalize verifies the transcript against its circuit and its phase 1 and returns its keys. The transcript needs at
+// least one contribution: δ = γ = 1 in the initial keys.
+func (c *Ceremony) Finalize(r1cs frontend.CompiledConstraintSystem, phase1 *PowersOfTau) (*VPKey, error) {
+	if r1cs == nil || phase1 == nil {
+		return nil, fmt.Errorf("finalize: the circuit and the powers of tau are required")
+	}
+	if len(c.Contributions) == 0 {
+		return nil, fmt.Errorf("%w: the transcript has no contribution", ErrInvalidContribution)
+	}
+	if err := c.Verify(r1cs, phase1); err != nil {
+		return nil, err
+	}
+	pk, vk, err := c.Current.keys(false)
+	if err != nil {
+		return nil, err
+	}
+	return CreateVPKey(pk, vk)
+}
+
+// verifyInitial checks that the initial keys are the ones derived from phase1
+func (c *Ceremony) verifyInitial(r1cs frontend.CompiledConstraintSystem, phase1 *PowersOfTau) error {
+	if r1cs == nil {
+		return fmt.Errorf("the circuit is required to check the initial keys")
+	}
+	if hash := phase1.Hash(); hash != c.PowersOfTau {
+		return fmt.Errorf("%w: powers of tau %s, ceremony is for %s", ErrInvalidContribution, hash, c.PowersOfTau)
+	}
+	if err := phase1.Verify(); err != nil {
+		return err
+	}
+	pk, vk, err := phase1.groth16Keys(r1cs)
+	if err != nil {
+		return err
+	}
+	params, err := newCeremonyParameters(pk, vk)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(params.ProvingKey, c.Initial.ProvingKey) || !bytes.Equal(params.VerifyingKey, c.Initial.VerifyingKey) {
+		return fmt.Errorf("%w: the initial keys are not derived from the powers of tau", ErrInvalidContribution)
+	}
+	return nil
+}
+
+func newCeremonyParameters(pk groth16.ProvingKey, vk groth16.VerifyingKey) (CeremonyParameters, error) {
+	var pkBuf, vkBuf bytes.Buffer
+	if _, err := pk.WriteRawTo(&pkBuf); err != nil {
+		return CeremonyParameters{}, err
+	}
+	if _, err := vk.WriteRawTo(&vkBuf); err != nil {
+		return CeremonyParameters{}, err
+	}
+	return CeremonyParameters{ProvingKey: pkBuf.Bytes(), VerifyingKey: vkBuf.Bytes()}, nil
+}
+
+func (p CeremonyParameters) keys(subgroupChecks bool) (groth16.ProvingKey, groth16.VerifyingKey, error) {
+	pk := groth16.NewProvingKey(ecc.BN254)
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	readPK, readVK := pk.UnsafeReadFrom, vk.UnsafeReadFrom
+	if subgroupChecks {
+		readPK, readVK = pk.ReadFrom, vk.ReadFrom
+	}
+	if _, err := readPK(bytes.NewReader(p.ProvingKey)); err != nil {
+		return nil, nil, err
+	}
+	if _, err := readVK(bytes.NewReader(p.VerifyingKey)); err != nil {
+		return nil, nil, err
+	}
+	return pk, vk, nil
+}
+
+// challenge binds a contribution to the circuit, the initial keys and all the previous contributions
+func (c *Ceremony) challenge(i int) []byte {
+	h := sha256.New()
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(c.Version))
+	h.Write(n[:])
+	h.Write([]byte(c.Circuit))
+	h.Write([]byte(c.R1CSHash))
+	h.Write([]byte(c.PowersOfTau))
+	pkHash := sha256.Sum256(c.Initial.ProvingKey)
+	vkHash := sha256.Sum256(c.Initial.VerifyingKey)
+	h.Write(pkHash[:])
+	h.Write(vkHash[:])
+	for _, contribution := range c.Contributions[:i] {
+		binary.BigEndian.PutUint64(n[:], uint64(len(contribution.Name)))
+		h.Write(n[:])
+		h.Write([]byte(contribution.Name))
+		h.Write(contribution.Delta)
+		h.Write(contribution.S)
+		h.Write(contribution.SX)
+		h.Write(contribution.RX)
+	}
+	return h.Sum(nil)
+}
+
+func ceremonyR(challenge []byte, s, sx *bn254.G1Affine) (bn254.G2Affine, error) {
+	sBytes, sxBytes := s.Bytes(), sx.Bytes()
+	msg := append(append(append([]byte{}, challenge...), sBytes[:]...), sxBytes[:]...)
+	return bn254.HashToCurveG2Svdw(msg, []byte(ceremonyDST))
+}
+
+func randomScalar(random io.Reader) (*big.Int, error) {
+	// 64 bytes reduced modulo r, the bias is negligible
+	var buf [64]byte
+	for {
+		if _, err := io.ReadFull(random, buf[:]); err != nil {
+			return nil, err
+		}
+		x := new(big.Int).SetBytes(buf[:])
+		x.Mod(x, fr.Modulus())
+		if x.Sign() != 0 {
+			return x, nil
+		}
+	}
+}
+
+func hashConstraintSystem(r1cs frontend.CompiledConstraintSystem) (string, error) {
+	var buf bytes.Buffer
+	if _, err := r1cs.WriteTo(&buf); err != nil {
+		return "", err
+	}
+	return checksum(buf.Bytes()), nil
+}
+
+// checkUnchangedParameters checks that everything but δ, K and Z is the same in the initial and current keys
+func checkUnchangedParameters(initialPK groth16.ProvingKey, initialVK groth16.VerifyingKey, currentPK groth16.ProvingKey, currentVK groth16.VerifyingKey) error {
+	ipk, err := provingKeyBN254(initialPK)
+	if err != nil {
+		return err
+	}
+	cpk, err := provingKeyBN254(currentPK)
+	if err != nil {
+		return err
+	}
+	ivk, err := verifyingKeyBN254(initialVK)
+	if err != nil {
+		return err
+	}
+	cvk, err := verifyingKeyBN254(currentVK)
+	if err != nil {
+		return err
+	}
+	unchanged := map[string][2]interface{}{
+		"pk [α]1":      {ipk.G1Alpha, cpk.G1Alpha},
+		"pk [β]1":      {ipk.G1Beta, cpk.G1Beta},
+		"pk [A]1":      {ipk.G1A, cpk.G1A},
+		"pk [B]1":      {ipk.G1B, cpk.G1B},
+		"pk [β]2":      {ipk.G2Beta, cpk.G2Beta},
+		"pk [B]2":      {ipk.G2B, cpk.G2B},
+		"pk infinityA": {ipk.InfinityA, cpk.InfinityA},
+		"pk infinityB": {ipk.InfinityB, cpk.InfinityB},
+		"pk domain":    {ipk.Domain.Cardinality, cpk.Domain.Cardinality},
+		"vk [K]1":      {ivk.G1K, cvk.G1K},
+		"vk [γ]2":      {ivk.G2Gamma, cvk.G2Gamma},
+	}
+	for name, values := range unchanged {
+		if !reflect.DeepEqual(values[0], values[1]) {
+			return fmt.Errorf("%w: %s changed", ErrInvalidContribution, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"gnark-bid/zk"
+	"log"
+	"os"
+	"strings"
+)
+
+const usage = `usage: ceremony <command> [flags]
+
+Trusted setup ceremony, fully offline: the transcript files are passed from one participant to the next.
+
+phase 1, powers of tau shared by all the circuits:
+  phase1-new         write the transcript of powers of tau without contribution
+  phase1-contribute  verify a powers of tau transcript, add your randomness and write the next transcript
+  phase1-verify      verify the whole chain of contributions of a powers of tau transcript
+
+phase 2, per circuit:
+  init        compile a registered circuit and write its initial transcript from the powers of tau
+  contribute  verify a transcript, add your randomness and write the next transcript
+  verify      verify the whole chain of contributions of a transcript
+  finalize    verify a transcript and write its keys to the artifact bundle and the Solidity verifier
+
+circuits: %s
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintf(os.Stderr, usage, strings.Join(zk.CircuitNames(), ", "))
+		os.Exit(2)
+	}
+	cmd, args := os.Args[1], os.Args[2:]
+	switch cmd {
+	case "phase1-new":
+		newPowersOfTau(args)
+	case "phase1-contribute":
+		contributePowersOfTau(args)
+	case "phase1-verify":
+		verifyPowersOfTau(args)
+	case "init":
+		initCeremony(args)
+	case "contribute":
+		contribute(args)
+	case "verify":
+		verify(args)
+	case "finalize":
+		finalize(args)
+	default:
+		fmt.Fprintf(os.Stderr, usage, strings.Join(zk.CircuitNames(), ", "))
+		os.Exit(2)
+	}
+}
+
+func newPowersOfTau(args []string) {
+	fs := flag.NewFlagSet("phase1-new", flag.ExitOnError)
+	power := fs.Int("power", 12, "circuits of at most 2^power constraints")
+	out := fs.String("out", "powersoftau.json", "transcript to write")
+	_ = fs.Parse(args)
+
+	p, err := zk.NewPowersOfTau(*power)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := p.WriteFile(*out); err != nil {
+		log.Fatal("write transcript error:", err)
+	}
+	fmt.Println("transcript written:", *out)
+}
+
+func contributePowersOfTau(args []string) {
+	fs := flag.NewFlagSet("phase1-contribute", flag.ExitOnError)
+	in := fs.String("in", "powersoftau.json", "transcript to contribute to")
+	out := fs.String("out", "", "transcript to write")
+	name := fs.String("name", "", "participant name, public")
+	_ = fs.Parse(args)
+	if *out == "" || *name == "" {
+		log.Fatal("phase1-contribute: -out and -name are required")
+	}
+
+	p := readPowersOfTau(*in)
+	if err := p.Verify(); err != nil {
+		log.Fatal("verify error:", err)
+	}
+	if err := p.Contribute(*name, nil); err != nil {
+		log.Fatal("contribute error:", err)
+	}
+	if err := p.WriteFile(*out); err != nil {
+		log.Fatal("write transcript error:", err)
+	}
+	last := p.Contributions[len(p.Contributions)-1]
+	fmt.Printf("contribution %d written: %s\n", len(p.Contributions), *out)
+	fmt.Printf("challenge: %x\n", last.Challenge)
+}
+
+func verifyPowersOfTau(args []string) {
+	fs := flag.NewFlagSet("phase1-verify", flag.ExitOnError)
+	in := fs.String("in", "powersoftau.json", "transcript to verify")
+	_ = fs.Parse(args)
+
+	p := readPowersOfTau(*in)
+	if err := p.Verify(); err != nil {
+		log.Fatal("verify error:", err)
+	}
+	fmt.Printf("powers of tau: 2^%d\n", p.Power)
+	for i, contribution := range p.Contributions {
+		fmt.Printf("contribution %d: %s, challenge %x\n", i+1, contribution.Name, contribution.Challenge)
+	}
+	fmt.Println("transcript ok:", p.Hash())
+}
+
+func initCeremony(args []string) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	circuitName := fs.String("circuit", "BiddingCircuit", "registered circuit")
+	ptau := fs.String("ptau", "powersoftau.json", "powers of tau transcript")
+	out := fs.String("out", "ceremony.json", "transcript to write")
+	_ = fs.Parse(args)
+
+	r1csCompiled := compile(*circuitName)
+	c, err := zk.NewCeremony(*circuitName, r1csCompiled, readPowersOfTau(*ptau))
+	if err != nil {
+		log.Fatal("setup error:", err)
+	}
+	if err := c.WriteFile(*out); err != nil {
+		log.Fatal("write transcript error:", err)
+	}
+	fmt.Println("transcript written:", *out)
+}
+
+func contribute(args []string) {
+	fs := flag.NewFlagSet("contribute", flag.ExitOnError)
+	in := fs.String("in", "ceremony.json", "transcript to contribute to")
+	out := fs.String("out", "", "transcript to write")
+	name := fs.String("name", "", "participant name, public")
+	_ = fs.Parse(args)
+	if *out == "" || *name == "" {
+		log.Fatal("contribute: -out and -name are required")
+	}
+
+	c := readTranscript(*in)
+	if err := c.Verify(nil, nil); err != nil {
+		log.Fatal("verify error:", err)
+	}
+	if err := c.Contribute(*name, nil); err != nil {
+		log.Fatal("contribute error:", err)
+	}
+	if err := c.WriteFile(*out); err != nil {
+		log.Fatal("write transcript error:", err)
+	}
+	last := c.Contributions[len(c.Contributions)-1]
+	fmt.Printf("contribution %d written: %s\n", len(c.Contributions), *out)
+	fmt.Printf("challenge: %x\n", last.Challenge)
+}
+
+func verify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	in := fs.String("in", "ceremony.json", "transcript to verify")
+	ptau := fs.String("ptau", "", "powers of tau transcript, to check the initial keys")
+	_ = fs.Parse(args)
+
+	c := readTranscript(*in)
+	var phase1 *zk.PowersOfTau
+	if *ptau != "" {
+		phase1 = readPowersOfTau(*ptau)
+	}
+	if err := c.Verify(compile(c.Circuit), phase1); err != nil {
+		log.Fatal("verify error:", err)
+	}
+	fmt.Println("circuit:", c.Circuit)
+	for i, contribution := range c.Contributions {
+		fmt.Printf("contribution %d: %s, challenge %x\n", i+1, contribution.Name, contribution.Challenge)
+	}
+	fmt.Println("transcript ok")
+}
+
+func finalize(args []string) {
+	fs := flag.NewFlagSet("finalize", flag.ExitOnError)
+	in := fs.String("in", "ceremony.json", "transcript to finalize")
+	ptau := fs.String("ptau", "powersoftau.json", "powers of tau transcript")
+	bundle := fs.String("bundle", zk.KeysDir, "artifact bundle directory")
+	solidity := fs.String("solidity", "solidity", "directory of the Solidity verifier")
+	_ = fs.Parse(args)
+
+	c := readTranscript(*in)
+	circuit, err := zk.NewCircuit(c.Circuit)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r1csCompiled := compile(c.Circuit)
+	vpKey, err := c.Finalize(r1csCompiled, readPowersOfTau(*ptau))
+	if err != nil {
+		log.Fatal("finalize error:", err)
+	}
+
+	fp, err := zk.NewFingerprint(c.Circuit, zk.CircuitVersion(circuit), r1csCompiled, vpKey.VK)
+	if err != nil {
+		log.Fatal("fingerprint error:", err)
+	}
+	if err := zk.WriteSolidityVerifier(*solidity, fp, vpKey.VK); err != nil {
+		log.Fatal("write verifier error:", err)
+	}
+	if err := zk.WriteBundle(*bundle, zk.BundleCircuit{
+		Name:    c.Circuit,
+		Version: zk.CircuitVersion(circuit),
+		R1CS:    r1csCompiled,
+		Key:     vpKey,
+	}); err != nil {
+		log.Fatal("write bundle error:", err)
+	}
+	fmt.Println("keys written:", *bundle, fp)
+}
+
+func readTranscript(fileName string) *zk.Ceremony {
+	c, err := zk.ReadCeremony(fileName)
+	if err != nil {
+		log.Fatal("read transcript error:", err)
+	}
+	return c
+}
+
+func readPowersOfTau(fileName string) *zk.PowersOfTau {
+	p, err := zk.ReadPowersOfTau(fileName)
+	if err != nil {
+		log.Fatal("read powers of tau error:", err)
+	}
+	return p
+}
+
+func compile(name string) frontend.CompiledConstraintSystem {
+	circuit, err := zk.NewCircuit(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r1csCompiled, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, circuit)
+	if err != nil {
+		log.Fatal("compile error:", err)
+	}
+	return r1csCompiled
+}
@@ -0,0 +1,98 @@
+package zk_test
+
+import (
+	"context"
+	"errors"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"gnark-bid/zk"
+	"gnark-bid/zk/circuits"
+	"math/big"
+	"path/filepath"
+	"testing"
+)
+
+func TestCeremony(t *testing.T) {
+	assert := test.NewAssert(t)
+	dir := t.TempDir()
+
+	circuit, err := zk.NewCircuit("PrivateValueCircuit")
+	assert.NoError(err)
+	r1csCompiled, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, circuit)
+	assert.NoError(err, "compilation failed")
+
+	phase1 := newPowersOfTau(t, 9, "carol")
+	_, err = zk.NewCeremony("PrivateValueCircuit", r1csCompiled, newPowersOfTau(t, 8, "carol"))
+	assert.Error(err, "too few powers of tau should fail")
+	c, err := zk.NewCeremony("PrivateValueCircuit", r1csCompiled, phase1)
+	assert.NoError(err, "initial setup failed")
+	assert.NoError(c.Verify(r1csCompiled, phase1), "initial transcript should verify")
+	_, err = c.Finalize(r1csCompiled, phase1)
+	assert.True(errors.Is(err, zk.ErrInvalidContribution), "finalizing without contribution should fail")
+
+	// participants only exchange files
+	for i, name := range []string{"alice", "bob"} {
+		in := filepath.Join(dir, "ceremony.json")
+		if i > 0 {
+			c, err = zk.ReadCeremony(in)
+			assert.NoError(err, "reading transcript failed")
+			assert.NoError(c.Verify(nil, nil), "transcript should verify")
+		}
+		assert.NoError(c.Contribute(name, nil), "contribution failed")
+		assert.NoError(c.WriteFile(in))
+	}
+	c, err = zk.ReadCeremony(filepath.Join(dir, "ceremony.json"))
+	assert.NoError(err)
+	assert.Equal(2, len(c.Contributions))
+	assert.NoError(c.Verify(r1csCompiled, phase1), "full chain should verify")
+
+	vpKey, err := c.Finalize(r1csCompiled, phase1)
+	assert.NoError(err, "finalize failed")
+	g16, err := zk.NewGnarkGroth16WithCS(vpKey, r1csCompiled, circuit)
+	assert.NoError(err)
+	assignment := zk_circuit.PrivateValueCircuit{
+		PrivateValue: 42,
+		Hash:         zk_circuit.HashMIMC(big.NewInt(42).Bytes()),
+	}
+	_, proof, err := g16.GenerateProof(context.Background(), &assignment)
+	assert.NoError(err, "proving with ceremony keys failed")
+	ok, err := g16.VerifyProof(&assignment, proof)
+	assert.NoError(err)
+	assert.True(ok, "proof should verify with ceremony keys")
+
+	// the proof of knowledge doesn't match another challenge
+	tampered := *c
+	tampered.Contributions = append([]zk.CeremonyContribution{}, c.Contributions...)
+	tampered.Contributions[0].Name = "mallory"
+	assert.True(errors.Is(tampered.Verify(nil, nil), zk.ErrInvalidContribution), "renamed contribution should fail")
+
+	// a contribution can't be dropped
+	tampered = *c
+	tampered.Contributions = c.Contributions[1:]
+	assert.True(errors.Is(tampered.Verify(nil, nil), zk.ErrInvalidContribution), "missing contribution should fail")
+
+	// current keys not derived from the contributions
+	otherPhase1 := newPowersOfTau(t, 9, "mallory")
+	other, err := zk.NewCeremony("PrivateValueCircuit", r1csCompiled, otherPhase1)
+	assert.NoError(err)
+	tampered = *c
+	tampered.Current = other.Current
+	assert.Error(tampered.Verify(nil, nil), "keys from another setup should fail")
+
+	// the initial keys are bound to the powers of tau
+	assert.Error(c.Verify(r1csCompiled, otherPhase1), "another phase 1 should fail")
+	tampered = *c
+	tampered.Initial = other.Initial
+	tampered.PowersOfTau = otherPhase1.Hash()
+	assert.Error(tampered.Verify(r1csCompiled, phase1), "initial keys of another phase 1 should fail")
+
+	// the transcript is bound to its circuit
+	var merkleCircuit zk_circuit.MerkleCircuit
+	merkleCircuit.Path = make([]frontend.Variable, 3)
+	merkleCircuit.Helper = make([]frontend.Variable, 2)
+	merkleCS, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &merkleCircuit)
+	assert.NoError(err)
+	assert.Error(c.Verify(merkleCS, nil), "another circuit should fail")
+}
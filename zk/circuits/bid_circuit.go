@@ -2,8 +2,6 @@ package zk_circuit
 
 import (
 	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/std/accumulator/merkle"
-	"github.com/consensys/gnark/std/hash/mimc"
 	"gnark-bid/circuits"
 )
 
@@ -26,20 +24,28 @@ type BiddingCircuit struct {
 	Identity Identity `gnark:",public"`
 
 	BidValue frontend.Variable `gnark:",public"`
+
+	// Hash is the hash of the users tree and of their leaves, DefaultHash when empty
+	Hash circuits.HashID `gnark:"-"`
+}
+
+// CircuitVersion is bumped whenever the constraints of the circuit change, it is part of the keys fingerprint
+func (circuit *BiddingCircuit) CircuitVersion() int {
+	return 1
 }
 
 func (circuit *BiddingCircuit) Define(api frontend.API) error {
 	api.AssertIsEqual(circuits.IsZero(api, circuit.BidValue), 0)
 
-	hFunc, err := mimc.NewMiMC(api)
+	hFunc, err := NewHash(api, circuit.Hash)
 	if err != nil {
 		return err
 	}
 
-	merkle.VerifyProof(api, hFunc, circuit.UserMerkleRoot, circuit.UserMerklePath, circuit.UserMerkleHelper)
+	circuits.VerifyMerkleProof(api, hFunc, circuit.UserMerkleRoot, circuit.UserMerklePath, circuit.UserMerkleHelper)
 
 	// preimage user id
-	userHash, err := HashPreImage(api, circuit.UserData.UserID)
+	userHash, err := HashPreImageWith(api, circuit.Hash, circuit.UserData.UserID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,55 @@
+package zk_circuit
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"gnark-bid/circuits"
+	"math/big"
+)
+
+// CommittedBidCircuit proves that Commitment, a Pedersen commitment (circuits.NativePedersenCommit), hides the private
+// bid whose salted MiMC hash is Hash (BidHash), so another proof or a sealed bid can bind the same bid. The commitments
+// add up: the sum of the commitments of a room opens to the total of its bids without opening any of them. Bids are at
+// most circuits.PedersenValueBits bits: Salt, a random element, keeps Hash from being brute-forced.
+type CommittedBidCircuit struct {
+	PrivateValue frontend.Variable
+	Salt         frontend.Variable
+	Blinding     frontend.Variable
+	Hash         frontend.Variable    `gnark:",public"`
+	Commitment   twistededwards.Point `gnark:",public"`
+}
+
+// Define declares the circuit's constraints
+func (circuit *CommittedBidCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(circuits.IsZero(api, circuit.PrivateValue), 0)
+
+	var err error
+	circuits.Gadget(api, circuits.GadgetMiMC, func() {
+		var m mimc.MiMC
+		if m, err = mimc.NewMiMC(api); err != nil {
+			return
+		}
+		m.Write(circuit.PrivateValue, circuit.Salt)
+		api.AssertIsEqual(circuit.Hash, m.Sum())
+	})
+	if err != nil {
+		return err
+	}
+
+	// the same private bid
+	commitment := circuits.PedersenCommit(api, circuit.PrivateValue, circuit.Blinding)
+	api.AssertIsEqual(commitment.X, circuit.Commitment.X)
+	api.AssertIsEqual(commitment.Y, circuit.Commitment.Y)
+
+	return nil
+}
+
+// BidHash is the Hash of a CommittedBidCircuit, MiMC(bid, salt) of reduced elements
+func BidHash(bid, salt *big.Int) *big.Int {
+	pre := make([]byte, 2*fr.Bytes)
+	bid.FillBytes(pre[:fr.Bytes])
+	salt.FillBytes(pre[fr.Bytes:])
+	return HashMIMC(pre)
+}
@@ -3,10 +3,15 @@ package zk_circuit
 import (
 	"github.com/consensys/gnark-crypto/hash"
 	"github.com/consensys/gnark/frontend"
+	gnarkHash "github.com/consensys/gnark/std/hash"
 	"github.com/consensys/gnark/std/hash/mimc"
+	"gnark-bid/circuits"
 	"math/big"
 )
 
+// DefaultHash is the hash of the circuits configured with an empty hash, the one of the embedded keys
+const DefaultHash = circuits.HashMiMC
+
 func HashMIMC(pre []byte) *big.Int {
 	h := hash.MIMC_BN254.New()
 	h.Write(pre)
@@ -14,12 +19,61 @@ func HashMIMC(pre []byte) *big.Int {
 	return new(big.Int).SetBytes(h.Sum(nil))
 }
 
-func HashPreImage(api frontend.API, variable frontend.Variable) (frontend.Variable, error) {
-	m, err := mimc.NewMiMC(api)
+// HashBytes is the native counterpart of HashPreImageWith: the hash id of the registry of pre, DefaultHash when id is
+// empty
+func HashBytes(id circuits.HashID, pre []byte) (*big.Int, error) {
+	hf, err := circuits.LookupHash(hashID(id))
 	if err != nil {
 		return nil, err
 	}
-	m.Write(variable)
+	h := hf.New()
+	if _, err := h.Write(pre); err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(h.Sum(nil)), nil
+}
 
-	return m.Sum(), nil
+func HashPreImage(api frontend.API, variable frontend.Variable) (res frontend.Variable, err error) {
+	circuits.Gadget(api, circuits.GadgetMiMC, func() {
+		var m mimc.MiMC
+		if m, err = mimc.NewMiMC(api); err != nil {
+			return
+		}
+		m.Write(variable)
+		res = m.Sum()
+	})
+	return res, err
+}
+
+// HashPreImageWith is HashPreImage with the hash id of the registry, DefaultHash when id is empty
+func HashPreImageWith(api frontend.API, id circuits.HashID, variable frontend.Variable) (res frontend.Variable, err error) {
+	hf, err := circuits.LookupHash(hashID(id))
+	if err != nil {
+		return nil, err
+	}
+	circuits.Gadget(api, hf.Gadget, func() {
+		var h gnarkHash.Hash
+		if h, err = hf.NewGadget(api); err != nil {
+			return
+		}
+		h.Write(variable)
+		res = h.Sum()
+	})
+	return res, err
+}
+
+// NewHash returns the gadget of the hash id of the registry, DefaultHash when id is empty
+func NewHash(api frontend.API, id circuits.HashID) (gnarkHash.Hash, error) {
+	hf, err := circuits.LookupHash(hashID(id))
+	if err != nil {
+		return nil, err
+	}
+	return hf.NewGadget(api)
+}
+
+func hashID(id circuits.HashID) circuits.HashID {
+	if id == "" {
+		return DefaultHash
+	}
+	return id
 }
@@ -2,21 +2,23 @@ package zk_circuit
 
 import (
 	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/std/accumulator/merkle"
-	"github.com/consensys/gnark/std/hash/mimc"
+	"gnark-bid/circuits"
 )
 
 type MerkleCircuit struct {
 	RootHash     frontend.Variable `gnark:",public"`
 	Path, Helper []frontend.Variable
+
+	// Hash is the hash of the tree, DefaultHash when empty
+	Hash circuits.HashID `gnark:"-"`
 }
 
 func (circuit *MerkleCircuit) Define(api frontend.API) error {
-	hFunc, err := mimc.NewMiMC(api)
+	hFunc, err := NewHash(api, circuit.Hash)
 	if err != nil {
 		return err
 	}
 
-	merkle.VerifyProof(api, hFunc, circuit.RootHash, circuit.Path, circuit.Helper)
+	circuits.VerifyMerkleProof(api, hFunc, circuit.RootHash, circuit.Path, circuit.Helper)
 	return nil
 }
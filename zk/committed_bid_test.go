@@ -0,0 +1,129 @@
+package zk_test
+
+import (
+	"context"
+	"crypto/rand"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	edwards "github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/test"
+	"gnark-bid/circuits"
+	"gnark-bid/zk"
+	"gnark-bid/zk/circuits"
+	"gnark-bid/zk/zktest"
+	"math/big"
+	"testing"
+)
+
+func committedBid(assert *test.Assert, bid int64) (*zk_circuit.CommittedBidCircuit, twistededwards.PointAffine, *big.Int) {
+	value := big.NewInt(bid)
+	blinding, err := circuits.NativePedersenBlinding()
+	assert.NoError(err)
+	c, err := circuits.NativePedersenCommit(value, blinding)
+	assert.NoError(err)
+	salt, err := rand.Int(rand.Reader, fr.Modulus())
+	assert.NoError(err)
+	return &zk_circuit.CommittedBidCircuit{
+		PrivateValue: value,
+		Salt:         salt,
+		Blinding:     blinding,
+		Hash:         zk_circuit.BidHash(value, salt),
+		Commitment:   edwards.Point{X: &c.X, Y: &c.Y},
+	}, c, blinding
+}
+
+func TestCommittedBidCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	s, err := zk.NewPublicInputSchema("CommittedBidCircuit")
+	assert.NoError(err)
+	assert.Equal([]string{"Hash", "Commitment.X", "Commitment.Y"}, s.Inputs)
+	ws, err := zk.NewWitnessSchema("CommittedBidCircuit")
+	assert.NoError(err)
+	assert.Equal([]zk.WitnessField{
+		{Path: "PrivateValue", Visibility: "secret"},
+		{Path: "Salt", Visibility: "secret"},
+		{Path: "Blinding", Visibility: "secret"},
+		{Path: "Hash", Visibility: "public"},
+		{Path: "Commitment.X", Visibility: "public"},
+		{Path: "Commitment.Y", Visibility: "public"},
+	}, ws.Fields)
+
+	circuit, err := zk.NewCircuit("CommittedBidCircuit")
+	assert.NoError(err)
+	r1csCompiled, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, circuit)
+	assert.NoError(err, "compilation failed")
+	pk, vk, err := zktest.UnsafeSeededSetup(r1csCompiled, []byte(t.Name()))
+	assert.NoError(err)
+	vpKey, err := zk.CreateVPKey(pk, vk)
+	assert.NoError(err)
+	g16, err := zk.NewGnarkGroth16WithCS(vpKey, r1csCompiled, circuit)
+	assert.NoError(err)
+
+	bids := []int64{120, 75, 300}
+	total, totalBlinding := new(big.Int), new(big.Int)
+	var commitments []twistededwards.PointAffine
+	for _, bid := range bids {
+		assignment, c, blinding := committedBid(assert, bid)
+		_, proof, err := g16.GenerateProof(context.Background(), assignment)
+		assert.NoError(err)
+		ok, err := g16.VerifyProof(assignment, proof)
+		assert.NoError(err)
+		assert.True(ok)
+
+		commitments = append(commitments, c)
+		total.Add(total, big.NewInt(bid))
+		totalBlinding.Add(totalBlinding, blinding)
+	}
+	// the auditor checks the total with the commitments of the proofs
+	assert.True(circuits.NativePedersenOpen(circuits.NativePedersenAdd(commitments...), total, totalBlinding))
+
+	// the commitment of another bid than the hashed one
+	assignment, _, _ := committedBid(assert, 120)
+	other, _, _ := committedBid(assert, 121)
+	assignment.Commitment = other.Commitment
+	assert.Error(test.IsSolved(circuit, assignment, ecc.BN254, backend.GROTH16))
+	_, _, err = g16.GenerateProof(context.Background(), assignment)
+	assert.Error(err)
+
+	// the hash of another salt
+	assignment, _, _ = committedBid(assert, 120)
+	assignment.Salt = new(big.Int).Add(assignment.Salt.(*big.Int), big.NewInt(1))
+	assert.Error(test.IsSolved(circuit, assignment, ecc.BN254, backend.GROTH16))
+}
+
+func TestCommittedBidPublicInputsHideTheBid(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	s, err := zk.NewPublicInputSchema("CommittedBidCircuit")
+	assert.NoError(err)
+
+	const bid = 120
+	a, _, _ := committedBid(assert, bid)
+	b, _, _ := committedBid(assert, bid)
+	inputsA, err := s.AssignmentPublicInputs(a)
+	assert.NoError(err)
+	inputsB, err := s.AssignmentPublicInputs(b)
+	assert.NoError(err)
+
+	// the same bid twice doesn't give the same public inputs
+	for i, name := range s.Inputs {
+		assert.NotEqual(inputsA.Vector()[i], inputsB.Vector()[i], name)
+	}
+
+	// hashing or committing the candidate bids without the salt and the blinding doesn't find the bid
+	for v := int64(0); v <= 2*bid; v++ {
+		candidate := big.NewInt(v)
+		unblinded, err := circuits.NativePedersenCommit(candidate, big.NewInt(0))
+		assert.NoError(err)
+		for _, input := range inputsA.Vector() {
+			assert.NotEqual(zk_circuit.HashMIMC(candidate.Bytes()), input, "bid %d", v)
+			assert.NotEqual(zk_circuit.BidHash(candidate, big.NewInt(0)), input, "bid %d", v)
+			assert.NotEqual(unblinded.X.ToBigIntRegular(new(big.Int)), input, "bid %d", v)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package zk
+
+import (
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/schema"
+	"reflect"
+)
+
+var tVariable = reflect.TypeOf((*frontend.Variable)(nil)).Elem()
+
+// CircuitSchema parses the public and secret inputs of a circuit
+func CircuitSchema(circuit frontend.Circuit) (*schema.Schema, error) {
+	return schema.Parse(circuit, tVariable, nil)
+}
+
+// CheckConstraintSystem checks that a compiled (or deserialized) constraint system is the one the keys were set up for,
+// and that it has the public and secret inputs of circuit
+func CheckConstraintSystem(r1cs frontend.CompiledConstraintSystem, pk groth16.ProvingKey, vk groth16.VerifyingKey, circuit frontend.Circuit) error {
+	if r1cs.CurveID() != ecc.BN254 {
+		return fmt.Errorf("r1cs: unsupported curve %s", r1cs.CurveID())
+	}
+	internal, secret, public := r1cs.GetNbVariables()
+
+	if circuit != nil {
+		s, err := CircuitSchema(circuit)
+		if err != nil {
+			return err
+		}
+		// the first public wire of the r1cs is the constant 1
+		if public != s.NbPublic+1 || secret != s.NbSecret {
+			return fmt.Errorf("%w: r1cs has %d public and %d secret inputs, circuit expects %d and %d", ErrKeyMismatch, public-1, secret, s.NbPublic, s.NbSecret)
+		}
+	}
+
+	if vk != nil && vk.NbPublicWitness() != public-1 {
+		return fmt.Errorf("%w: verifying key expects %d public inputs, r1cs has %d", ErrKeyMismatch, vk.NbPublicWitness(), public-1)
+	}
+
+	if pk != nil {
+		nbWires, nbPrivateWires, cardinality, err := provingKeyDimensions(pk)
+		if err != nil {
+			return err
+		}
+		if nbWires != internal+secret+public || nbPrivateWires != internal+secret {
+			return fmt.Errorf("%w: proving key has %d wires (%d private), r1cs has %d (%d private)", ErrKeyMismatch, nbWires, nbPrivateWires, internal+secret+public, internal+secret)
+		}
+		if want := ecc.NextPowerOfTwo(uint64(r1cs.GetNbConstraints())); cardinality != want {
+			return fmt.Errorf("%w: proving key domain has size %d, r1cs needs %d", ErrKeyMismatch, cardinality, want)
+		}
+	}
+
+	return nil
+}
+
+// provingKeyDimensions reads the number of wires, private wires and the domain size of a groth16 proving key
+func provingKeyDimensions(pk groth16.ProvingKey) (nbWires, nbPrivateWires int, cardinality uint64, err error) {
+	_pk, err := provingKeyBN254(pk)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return len(*_pk.InfinityA), len(*_pk.G1K), _pk.Domain.Cardinality, nil
+}
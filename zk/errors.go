@@ -0,0 +1,91 @@
+package zk
+
+import (
+	"context"
+	"errors"
+)
+
+// Errors returned (wrapped) by the provers, verifiers and bidding sessions, test them with errors.Is
+var (
+	// ErrSessionNotReady is returned by the bidding session before InitSession
+	ErrSessionNotReady = errors.New("session is not ready")
+	// ErrNotMember is returned when the user isn't a leaf of the merkle tree of the room
+	ErrNotMember = errors.New("user is not a member of the room")
+	// ErrBidOutOfRange is returned for a bid that isn't a positive field element
+	ErrBidOutOfRange = errors.New("bid out of range")
+	// ErrInvalidWitness is returned when an assignment doesn't fit the circuit
+	ErrInvalidWitness = errors.New("invalid witness")
+	// ErrUnsatisfiedConstraints is returned when the witness doesn't satisfy the constraints of the circuit
+	ErrUnsatisfiedConstraints = errors.New("unsatisfied constraints")
+	// ErrInvalidPublicInput is returned when the public inputs given to a verifier are missing or malformed
+	ErrInvalidPublicInput = errors.New("invalid public input")
+	// ErrProofInvalid is returned for a proof that can't be decoded or doesn't verify
+	ErrProofInvalid = errors.New("invalid proof")
+	// ErrKeyMismatch is returned when the keys, the constraint system or the verifier don't belong together
+	ErrKeyMismatch = errors.New("key mismatch")
+)
+
+// kindError is a sentinel error that is also one of a broader kind, e.g. ErrFingerprintMismatch is an ErrKeyMismatch
+type kindError struct {
+	msg  string
+	kind error
+}
+
+func (e *kindError) Error() string { return e.msg }
+
+func (e *kindError) Is(target error) bool { return target == e.kind }
+
+// Code is a stable identifier of an error, for the wasm bindings and the servers to report errors to their clients
+type Code string
+
+const (
+	CodeSessionNotReady        Code = "SESSION_NOT_READY"
+	CodeNotMember              Code = "NOT_MEMBER"
+	CodeBidOutOfRange          Code = "BID_OUT_OF_RANGE"
+	CodeInvalidWitness         Code = "INVALID_WITNESS"
+	CodeUnsatisfiedConstraints Code = "UNSATISFIED_CONSTRAINTS"
+	CodeInvalidPublicInput     Code = "INVALID_PUBLIC_INPUT"
+	CodeProofInvalid           Code = "PROOF_INVALID"
+	CodeFingerprintMismatch    Code = "FINGERPRINT_MISMATCH"
+	CodeKeyMismatch            Code = "KEY_MISMATCH"
+	CodeInvalidContribution    Code = "INVALID_CONTRIBUTION"
+	CodePoolFull               Code = "POOL_FULL"
+	CodePoolClosed             Code = "POOL_CLOSED"
+	CodeCanceled               Code = "CANCELED"
+	CodeDeadlineExceeded       Code = "DEADLINE_EXCEEDED"
+	CodeInternal               Code = "INTERNAL"
+)
+
+// the most specific errors come first
+var errorCodes = []struct {
+	err  error
+	code Code
+}{
+	{ErrSessionNotReady, CodeSessionNotReady},
+	{ErrNotMember, CodeNotMember},
+	{ErrBidOutOfRange, CodeBidOutOfRange},
+	{ErrInvalidWitness, CodeInvalidWitness},
+	{ErrUnsatisfiedConstraints, CodeUnsatisfiedConstraints},
+	{ErrInvalidPublicInput, CodeInvalidPublicInput},
+	{ErrProofInvalid, CodeProofInvalid},
+	{ErrFingerprintMismatch, CodeFingerprintMismatch},
+	{ErrKeyMismatch, CodeKeyMismatch},
+	{ErrInvalidContribution, CodeInvalidContribution},
+	{ErrPoolFull, CodePoolFull},
+	{ErrPoolClosed, CodePoolClosed},
+	{context.Canceled, CodeCanceled},
+	{context.DeadlineExceeded, CodeDeadlineExceeded},
+}
+
+// ErrorCode returns the code of err, CodeInternal for unknown errors and "" for nil
+func ErrorCode(err error) Code {
+	if err == nil {
+		return ""
+	}
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return CodeInternal
+}
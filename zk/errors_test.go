@@ -0,0 +1,68 @@
+package zk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"gnark-bid/zk"
+	"math/big"
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assert.Equal(zk.Code(""), zk.ErrorCode(nil))
+	assert.Equal(zk.CodeInternal, zk.ErrorCode(errors.New("boom")))
+	assert.Equal(zk.CodeNotMember, zk.ErrorCode(fmt.Errorf("%w: alice", zk.ErrNotMember)))
+	assert.Equal(zk.CodeCanceled, zk.ErrorCode(fmt.Errorf("proving: %w", context.Canceled)))
+
+	// a fingerprint mismatch is a key mismatch with its own code
+	assert.ErrorIs(zk.ErrFingerprintMismatch, zk.ErrKeyMismatch)
+	assert.Equal(zk.CodeFingerprintMismatch, zk.ErrorCode(fmt.Errorf("%w: keys are x", zk.ErrFingerprintMismatch)))
+	assert.Equal(zk.CodeKeyMismatch, zk.ErrorCode(fmt.Errorf("%w: domain", zk.ErrKeyMismatch)))
+}
+
+func TestBiddingErrors(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	bidding, err := zk.NewBidding(nil)
+	assert.NoError(err)
+
+	_, _, err = bidding.GetProof(big.NewInt(100))
+	assert.ErrorIs(err, zk.ErrSessionNotReady)
+	_, err = bidding.VerifyProof(&zk.Proof{}, [5]*big.Int{})
+	assert.ErrorIs(err, zk.ErrSessionNotReady)
+
+	assert.NoError(bidding.InitSession(1111, "username_2", big.NewInt(1111222233334444)))
+	for _, bid := range []*big.Int{nil, big.NewInt(0), big.NewInt(-1), fr.Modulus()} {
+		_, _, err = bidding.GetProof(bid)
+		assert.ErrorIs(err, zk.ErrBidOutOfRange, "bid %v", bid)
+	}
+
+	proof, inputs, err := bidding.GetProof(big.NewInt(100))
+	assert.NoError(err)
+	_, err = bidding.VerifyProof(nil, inputs)
+	assert.ErrorIs(err, zk.ErrProofInvalid)
+	_, err = bidding.VerifyProof(proof, [5]*big.Int{inputs[0]})
+	assert.ErrorIs(err, zk.ErrInvalidPublicInput)
+
+	// another bid with the same proof
+	tampered := inputs
+	tampered[4] = big.NewInt(101)
+	ok, err := bidding.VerifyProof(proof, tampered)
+	assert.False(ok)
+	assert.ErrorIs(err, zk.ErrProofInvalid)
+	assert.Equal(zk.CodeProofInvalid, zk.ErrorCode(err))
+
+	// a user missing from the room list
+	assert.NoError(bidding.InitSession(1111, "mallory", big.NewInt(1)))
+	_, _, err = bidding.GetProof(big.NewInt(100))
+	assert.ErrorIs(err, zk.ErrNotMember)
+
+	assert.NoError(bidding.InitSession(1111, "username_2", big.NewInt(1111222233334444)))
+	assert.ErrorIs(bidding.SetVerifierFingerprint(common.Hash{}), zk.ErrKeyMismatch)
+}
@@ -0,0 +1,214 @@
+package zk
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/compiled"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// ErrFingerprintMismatch is returned when the keys, the constraint system and the verifier don't belong together,
+// it is an ErrKeyMismatch
+var ErrFingerprintMismatch error = &kindError{msg: "fingerprint mismatch", kind: ErrKeyMismatch}
+
+// VersionedCircuit is implemented by circuits that version their constraints. Circuits without it are version 1.
+type VersionedCircuit interface {
+	CircuitVersion() int
+}
+
+// Fingerprint identifies a circuit and the keys it was set up with
+type Fingerprint struct {
+	Circuit  string `json:"circuit"`
+	Version  int    `json:"version"`
+	VKHash   string `json:"vkHash"`
+	R1CSHash string `json:"r1csHash"`
+}
+
+// CircuitVersion returns the version of a circuit
+func CircuitVersion(circuit frontend.Circuit) int {
+	if v, ok := circuit.(VersionedCircuit); ok {
+		return v.CircuitVersion()
+	}
+	return 1
+}
+
+// CircuitName returns the name of the struct of a circuit, as used in bundles and contract names
+func CircuitName(circuit frontend.Circuit) string {
+	t := reflect.TypeOf(circuit)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// NewFingerprint hashes the raw verifying key and the constraints of the constraint system (r1csDigest)
+func NewFingerprint(name string, version int, r1cs frontend.CompiledConstraintSystem, vk groth16.VerifyingKey) (Fingerprint, error) {
+	var vkBuf bytes.Buffer
+	if _, err := vk.WriteRawTo(&vkBuf); err != nil {
+		return Fingerprint{}, err
+	}
+	r1csHash, err := r1csDigest(r1cs)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	if version == 0 {
+		version = 1
+	}
+	return Fingerprint{
+		Circuit:  name,
+		Version:  version,
+		VKHash:   checksum(vkBuf.Bytes()),
+		R1CSHash: r1csHash,
+	}, nil
+}
+
+// r1csDigest is the sha256 of the variable counts, the constraints and the coefficients of a constraint system, as
+// big-endian uint64 and 32 bytes elements. The serialization of gnark also has the debug info (source files and
+// lines) and the names of the inputs, which change with the code but not with the keys.
+func r1csDigest(r1cs frontend.CompiledConstraintSystem) (string, error) {
+	constraints, coefficients, err := r1csBN254(r1cs)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	writeUint64 := func(v uint64) {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], v)
+		h.Write(b[:])
+	}
+	internal, secret, public := r1cs.GetNbVariables()
+	for _, v := range []int{internal, secret, public, len(constraints)} {
+		writeUint64(uint64(v))
+	}
+	for _, c := range constraints {
+		for _, l := range []compiled.LinearExpression{c.L, c.R, c.O} {
+			writeUint64(uint64(len(l)))
+			for _, t := range l {
+				writeUint64(uint64(t))
+			}
+		}
+	}
+	writeUint64(uint64(len(coefficients)))
+	for i := range coefficients {
+		b := coefficients[i].Bytes()
+		h.Write(b[:])
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Digest is the value stored in the Solidity verifier and the Go bindings:
+// keccak256(circuit ∥ version (uint64 big endian) ∥ sha256(vk) ∥ r1csDigest(r1cs))
+func (f Fingerprint) Digest() common.Hash {
+	var version [8]byte
+	binary.BigEndian.PutUint64(version[:], uint64(f.Version))
+	vkHash, _ := hex.DecodeString(f.VKHash)
+	r1csHash, _ := hex.DecodeString(f.R1CSHash)
+	return crypto.Keccak256Hash([]byte(f.Circuit), version[:], vkHash, r1csHash)
+}
+
+func (f Fingerprint) String() string {
+	return fmt.Sprintf("%s v%d %s", f.Circuit, f.Version, f.Digest().Hex())
+}
+
+// Check compares the fingerprint with the digest of a deployed verifier
+func (f Fingerprint) Check(verifierDigest common.Hash) error {
+	if f.Digest() != verifierDigest {
+		return fmt.Errorf("%w: keys are %s, verifier is %s", ErrFingerprintMismatch, f, verifierDigest.Hex())
+	}
+	return nil
+}
+
+// ExportSolidity writes the Solidity verifier of vk with the fingerprint in its header and as a FINGERPRINT constant
+func (f Fingerprint) ExportSolidity(w io.Writer, vk groth16.VerifyingKey) error {
+	var buf bytes.Buffer
+	if err := vk.ExportSolidity(&buf); err != nil {
+		return err
+	}
+	src := buf.String()
+
+	const pragma = "pragma solidity"
+	const contract = "contract Verifier {\n"
+	if !strings.Contains(src, pragma) || !strings.Contains(src, contract) {
+		return fmt.Errorf("unexpected solidity verifier layout")
+	}
+	header := fmt.Sprintf("// Circuit: %s v%d\n// VK sha256: %s\n// R1CS sha256: %s\n// Fingerprint: %s\n\n",
+		f.Circuit, f.Version, f.VKHash, f.R1CSHash, f.Digest().Hex())
+	src = strings.Replace(src, pragma, header+pragma, 1)
+	src = strings.Replace(src, contract, contract+fmt.Sprintf("\n    bytes32 public constant FINGERPRINT = %s;\n", f.Digest().Hex()), 1)
+
+	_, err := io.WriteString(w, src)
+	return err
+}
+
+// fingerprintABI is the getter of the FINGERPRINT constant added by ExportSolidity
+var fingerprintABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(`[{"inputs":[],"name":"FINGERPRINT","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"}]`))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// ReadVerifierFingerprint reads the FINGERPRINT constant of the verifier deployed at address
+func ReadVerifierFingerprint(ctx context.Context, caller bind.ContractCaller, address common.Address) (common.Hash, error) {
+	contract := bind.NewBoundContract(address, fingerprintABI, caller, nil, nil)
+	var out []interface{}
+	if err := contract.Call(&bind.CallOpts{Context: ctx}, &out, "FINGERPRINT"); err != nil {
+		return common.Hash{}, fmt.Errorf("reading the fingerprint of the verifier %s: %w", address.Hex(), err)
+	}
+	return common.Hash(*abi.ConvertType(out[0], new([32]byte)).(*[32]byte)), nil
+}
+
+var bindingsTemplate = template.Must(template.New("fingerprint").Parse(`// Code generated by zk.WriteFingerprintBindings. DO NOT EDIT.
+
+package {{.Package}}
+
+// {{.Name}}Fingerprint is the fingerprint of the circuit and keys of Contract_{{.Name}}.sol
+const {{.Name}}Fingerprint = "{{.Digest}}"
+`))
+
+// WriteFingerprintBindings writes a Go file declaring the fingerprint digest next to the abigen bindings
+func WriteFingerprintBindings(fileName, pkg string, f Fingerprint) error {
+	var buf bytes.Buffer
+	if err := bindingsTemplate.Execute(&buf, map[string]string{
+		"Package": pkg,
+		"Name":    f.Circuit,
+		"Digest":  f.Digest().Hex(),
+	}); err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, buf.Bytes(), 0o644)
+}
+
+// checkKeyPair checks that the proving and verifying keys come from the same setup
+func checkKeyPair(pk groth16.ProvingKey, vk groth16.VerifyingKey) error {
+	_pk, err := provingKeyBN254(pk)
+	if err != nil {
+		return err
+	}
+	_vk, err := verifyingKeyBN254(vk)
+	if err != nil {
+		return err
+	}
+	if !_pk.G1Alpha.Equal(_vk.G1Alpha) || !_pk.G2Beta.Equal(_vk.G2Beta) {
+		return fmt.Errorf("%w: proving and verifying keys have different [α]1, [β]2", ErrFingerprintMismatch)
+	}
+	if !_pk.G1Delta.Equal(_vk.G1Delta) || !_pk.G2Delta.Equal(_vk.G2Delta) {
+		return fmt.Errorf("%w: proving and verifying keys have different [δ]1, [δ]2", ErrFingerprintMismatch)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package zk
+
+import (
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/compiled"
+	"reflect"
+)
+
+// The concrete groth16 types of gnark live in an internal package. Their exported fields are reached through
+// reflection and exposed with the gnark-crypto bn254 types, the pointers alias the fields of the keys.
+
+type bn254ProvingKey struct {
+	Domain *fft.Domain
+
+	G1Alpha, G1Beta, G1Delta *bn254.G1Affine
+	G1A, G1B, G1Z, G1K       *[]bn254.G1Affine
+
+	G2Beta, G2Delta *bn254.G2Affine
+	G2B             *[]bn254.G2Affine
+
+	InfinityA, InfinityB     *[]bool
+	NbInfinityA, NbInfinityB *uint64
+}
+
+type bn254VerifyingKey struct {
+	G1Alpha, G1Beta, G1Delta *bn254.G1Affine
+	G1K                      *[]bn254.G1Affine
+
+	G2Beta, G2Delta, G2Gamma *bn254.G2Affine
+}
+
+type bn254Proof struct {
+	Ar, Krs *bn254.G1Affine
+	Bs      *bn254.G2Affine
+}
+
+func provingKeyBN254(pk groth16.ProvingKey) (res *bn254ProvingKey, err error) {
+	defer recoverLayout(pk, &err)
+	v := structValue(pk)
+	return &bn254ProvingKey{
+		Domain:      fieldPtr(v, "Domain").(*fft.Domain),
+		G1Alpha:     fieldPtr(v, "G1", "Alpha").(*bn254.G1Affine),
+		G1Beta:      fieldPtr(v, "G1", "Beta").(*bn254.G1Affine),
+		G1Delta:     fieldPtr(v, "G1", "Delta").(*bn254.G1Affine),
+		G1A:         fieldPtr(v, "G1", "A").(*[]bn254.G1Affine),
+		G1B:         fieldPtr(v, "G1", "B").(*[]bn254.G1Affine),
+		G1Z:         fieldPtr(v, "G1", "Z").(*[]bn254.G1Affine),
+		G1K:         fieldPtr(v, "G1", "K").(*[]bn254.G1Affine),
+		G2Beta:      fieldPtr(v, "G2", "Beta").(*bn254.G2Affine),
+		G2Delta:     fieldPtr(v, "G2", "Delta").(*bn254.G2Affine),
+		G2B:         fieldPtr(v, "G2", "B").(*[]bn254.G2Affine),
+		InfinityA:   fieldPtr(v, "InfinityA").(*[]bool),
+		InfinityB:   fieldPtr(v, "InfinityB").(*[]bool),
+		NbInfinityA: fieldPtr(v, "NbInfinityA").(*uint64),
+		NbInfinityB: fieldPtr(v, "NbInfinityB").(*uint64),
+	}, nil
+}
+
+func verifyingKeyBN254(vk groth16.VerifyingKey) (res *bn254VerifyingKey, err error) {
+	defer recoverLayout(vk, &err)
+	v := structValue(vk)
+	return &bn254VerifyingKey{
+		G1Alpha: fieldPtr(v, "G1", "Alpha").(*bn254.G1Affine),
+		G1Beta:  fieldPtr(v, "G1", "Beta").(*bn254.G1Affine),
+		G1Delta: fieldPtr(v, "G1", "Delta").(*bn254.G1Affine),
+		G1K:     fieldPtr(v, "G1", "K").(*[]bn254.G1Affine),
+		G2Beta:  fieldPtr(v, "G2", "Beta").(*bn254.G2Affine),
+		G2Delta: fieldPtr(v, "G2", "Delta").(*bn254.G2Affine),
+		G2Gamma: fieldPtr(v, "G2", "Gamma").(*bn254.G2Affine),
+	}, nil
+}
+
+func proofBN254(proof groth16.Proof) (res *bn254Proof, err error) {
+	defer recoverLayout(proof, &err)
+	v := structValue(proof)
+	return &bn254Proof{
+		Ar:  fieldPtr(v, "Ar").(*bn254.G1Affine),
+		Krs: fieldPtr(v, "Krs").(*bn254.G1Affine),
+		Bs:  fieldPtr(v, "Bs").(*bn254.G2Affine),
+	}, nil
+}
+
+// r1csBN254 returns the constraints and the coefficients of a bn254 constraint system
+func r1csBN254(r1cs frontend.CompiledConstraintSystem) (constraints []compiled.R1C, coefficients []fr.Element, err error) {
+	defer recoverLayout(r1cs, &err)
+	if r1cs.CurveID() != ecc.BN254 {
+		return nil, nil, fmt.Errorf("r1cs: unsupported curve %s", r1cs.CurveID())
+	}
+	v := structValue(r1cs)
+	return *fieldPtr(v, "Constraints").(*[]compiled.R1C), *fieldPtr(v, "Coefficients").(*[]fr.Element), nil
+}
+
+func structValue(i interface{}) reflect.Value {
+	v := reflect.ValueOf(i)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic("not a pointer to a struct")
+	}
+	return v.Elem()
+}
+
+func fieldPtr(v reflect.Value, path ...string) interface{} {
+	for _, name := range path {
+		v = v.FieldByName(name)
+		if !v.IsValid() {
+			panic("missing field " + name)
+		}
+	}
+	return v.Addr().Interface()
+}
+
+// recoverLayout turns a panic raised while reading an unexpected layout (other curve, other gnark version) into an error
+func recoverLayout(i interface{}, err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("unsupported groth16 type %T: %v", i, r)
+	}
+}
+
+// witnessBN254 returns the vector of a (public or full) witness (regular fr.Element, i.e. Montgomery form)
+func witnessBN254(w *witness.Witness) (res []fr.Element, err error) {
+	defer recoverLayout(w.Vector, &err)
+	v := reflect.ValueOf(w.Vector)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	return v.Convert(reflect.TypeOf([]fr.Element{})).Interface().([]fr.Element), nil
+}
@@ -0,0 +1,15 @@
+//go:build !zk_noembed
+
+package zk
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed keys
+var embeddedKeys embed.FS
+
+func embeddedBundle() (fs.FS, error) {
+	return fs.Sub(embeddedKeys, "keys")
+}
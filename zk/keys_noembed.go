@@ -0,0 +1,12 @@
+//go:build zk_noembed
+
+package zk
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+func embeddedBundle() (fs.FS, error) {
+	return nil, fmt.Errorf("keys are not embedded (built with zk_noembed), use LoadBundle")
+}
@@ -0,0 +1,221 @@
+package zk
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"sync"
+)
+
+var (
+	// ErrPoolClosed is returned for the jobs submitted to, or still queued in, a closed pool
+	ErrPoolClosed = errors.New("prover pool closed")
+	// ErrPoolFull is returned when the queue of the pool is at PoolConfig.MaxQueue
+	ErrPoolFull = errors.New("prover pool queue full")
+)
+
+// PoolConfig bounds the resources of a ProverPool
+type PoolConfig struct {
+	Workers   int    // proofs generated concurrently, 1 when zero
+	MaxMemory uint64 // bytes the running proofs may use (estimated, see ProofMemory), no bound when zero
+	MaxQueue  int    // jobs waiting for a worker, no bound when zero
+}
+
+// ProofResult is the outcome of a job of a ProverPool
+type ProofResult struct {
+	Proof      *Proof
+	Groth16    groth16.Proof
+	Err        error
+	Assignment frontend.Circuit
+}
+
+// ProverPool generates proofs with one shared GnarkGroth16 (keys and constraint system loaded once). Queued jobs
+// run by decreasing priority, in submission order for the same priority.
+type ProverPool struct {
+	g16     *GnarkGroth16
+	workers int
+	cfg     PoolConfig
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  jobQueue
+	seq    uint64
+	closed bool
+	wg     sync.WaitGroup
+}
+
+type proofJob struct {
+	ctx        context.Context
+	priority   int
+	seq        uint64
+	assignment frontend.Circuit
+	opts       []ProveOption
+	done       chan ProofResult
+}
+
+// NewProverPool starts the workers of the pool, the number of workers is lowered so that their estimated memory
+// stays below cfg.MaxMemory
+func NewProverPool(g16 *GnarkGroth16, cfg PoolConfig) (*ProverPool, error) {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	if cfg.MaxMemory > 0 {
+		perProof, err := g16.ProofMemory()
+		if err != nil {
+			return nil, err
+		}
+		if cfg.MaxMemory < perProof {
+			return nil, fmt.Errorf("max memory %d is below the %d bytes of one proof", cfg.MaxMemory, perProof)
+		}
+		if n := cfg.MaxMemory / perProof; n < uint64(workers) {
+			workers = int(n)
+		}
+	}
+
+	p := &ProverPool{g16: g16, workers: workers, cfg: cfg}
+	p.cond = sync.NewCond(&p.mu)
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p, nil
+}
+
+// Workers returns the number of proofs generated concurrently
+func (p *ProverPool) Workers() int {
+	return p.workers
+}
+
+// Submit queues the proof of assignment, the result is sent on the returned channel. A job whose ctx is done before
+// it runs is dropped with ctx.Err().
+func (p *ProverPool) Submit(ctx context.Context, priority int, assignment frontend.Circuit, opts ...ProveOption) (<-chan ProofResult, error) {
+	job := &proofJob{
+		ctx:        ctx,
+		priority:   priority,
+		assignment: assignment,
+		opts:       opts,
+		done:       make(chan ProofResult, 1),
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+	if p.cfg.MaxQueue > 0 && p.queue.Len() >= p.cfg.MaxQueue {
+		return nil, ErrPoolFull
+	}
+	job.seq = p.seq
+	p.seq++
+	heap.Push(&p.queue, job)
+	p.cond.Signal()
+	return job.done, nil
+}
+
+// Prove submits assignment and waits for its proof
+func (p *ProverPool) Prove(ctx context.Context, priority int, assignment frontend.Circuit, opts ...ProveOption) (*Proof, groth16.Proof, error) {
+	done, err := p.Submit(ctx, priority, assignment, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	select {
+	case res := <-done:
+		return res.Proof, res.Groth16, res.Err
+	case <-ctx.Done():
+		// the worker drops or aborts the job, done is buffered so it never blocks
+		return nil, nil, ctx.Err()
+	}
+}
+
+// Queued returns the number of jobs waiting for a worker
+func (p *ProverPool) Queued() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.queue.Len()
+}
+
+// Close fails the queued jobs with ErrPoolClosed and waits for the running ones
+func (p *ProverPool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	for p.queue.Len() > 0 {
+		job := heap.Pop(&p.queue).(*proofJob)
+		job.done <- ProofResult{Err: ErrPoolClosed, Assignment: job.assignment}
+	}
+	p.cond.Broadcast()
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+func (p *ProverPool) work() {
+	defer p.wg.Done()
+	for {
+		p.mu.Lock()
+		for p.queue.Len() == 0 && !p.closed {
+			p.cond.Wait()
+		}
+		if p.closed {
+			p.mu.Unlock()
+			return
+		}
+		job := heap.Pop(&p.queue).(*proofJob)
+		p.mu.Unlock()
+
+		res := ProofResult{Assignment: job.assignment}
+		var proverDone <-chan struct{}
+		if res.Err = job.ctx.Err(); res.Err == nil {
+			opts := append(job.opts[:len(job.opts):len(job.opts)], withProverDone(&proverDone))
+			res.Proof, res.Groth16, res.Err = p.g16.GenerateProof(job.ctx, job.assignment, opts...)
+		}
+		job.done <- res
+		// a cancelled proof still runs in gnark, the worker waits for it to stay in the memory bound
+		if proverDone != nil {
+			<-proverDone
+		}
+	}
+}
+
+// ProofMemory estimates the bytes allocated by one proof: the a, b, c vectors over the FFT domain and the copies of
+// the wire values used by the multi-exponentiations
+func (t *GnarkGroth16) ProofMemory() (uint64, error) {
+	pk, err := provingKeyBN254(t.pk)
+	if err != nil {
+		return 0, err
+	}
+	internal, secret, public := t.r1cs.GetNbVariables()
+	nbWires := uint64(internal + secret + public)
+	return (3*pk.Domain.Cardinality + 3*nbWires) * fr.Bytes, nil
+}
+
+// jobQueue is a heap of jobs, highest priority first then oldest first
+type jobQueue []*proofJob
+
+func (q jobQueue) Len() int { return len(q) }
+
+func (q jobQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q jobQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *jobQueue) Push(x interface{}) { *q = append(*q, x.(*proofJob)) }
+
+func (q *jobQueue) Pop() interface{} {
+	old := *q
+	job := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return job
+}
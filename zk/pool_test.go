@@ -0,0 +1,161 @@
+package zk_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	"gnark-bid/zk"
+	"gnark-bid/zk/circuits"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestProverPoolBidding(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	bundle, err := zk.DefaultBundle()
+	assert.NoError(err)
+	var circuit zk_circuit.BiddingCircuit
+	circuit.UserMerklePath = make([]frontend.Variable, zk.MerkleTreeDepth+1)
+	circuit.UserMerkleHelper = make([]frontend.Variable, zk.MerkleTreeDepth)
+	g16, err := bundle.GnarkGroth16("BiddingCircuit", &circuit)
+	assert.NoError(err, "loading prover from bundle failed")
+
+	pool, err := zk.NewProverPool(g16, zk.PoolConfig{Workers: 4})
+	assert.NoError(err)
+	defer pool.Close()
+
+	const nbUsers = 8
+	var wg sync.WaitGroup
+	errs := make([]error, nbUsers)
+	for i := 0; i < nbUsers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = func() error {
+				bidding, err := zk.NewBiddingWithProver(g16)
+				if err != nil {
+					return err
+				}
+				if err := bidding.InitSession(1111, fmt.Sprintf("username_%d", i+1), big.NewInt(int64(1000+i))); err != nil {
+					return err
+				}
+				assignment, inputs, err := bidding.Assignment(big.NewInt(int64(100 + i)))
+				if err != nil {
+					return err
+				}
+				// renewing the session doesn't change the witness being proven
+				if err := bidding.RenewSession(); err != nil {
+					return err
+				}
+				_, proof, err := pool.Prove(context.Background(), i%3, assignment)
+				if err != nil {
+					return err
+				}
+				if ok, err := g16.VerifyProof(assignment, proof); !ok || err != nil {
+					return fmt.Errorf("proof of user %d should verify: %v", i+1, err)
+				}
+				if inputs[1].Cmp(bidding.GetIdentity().Nullifier) == 0 {
+					return fmt.Errorf("renewed session of user %d should have a new nullifier", i+1)
+				}
+				return nil
+			}()
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(err)
+	}
+
+	// the verifier of a session doesn't leak to the other sessions of the prover
+	stale, err := zk.NewBiddingWithProver(g16)
+	assert.NoError(err)
+	assert.NoError(stale.InitSession(1111, "username_1", big.NewInt(1000)))
+	assert.ErrorIs(stale.SetVerifierFingerprint(common.HexToHash("0x01")), zk.ErrFingerprintMismatch)
+	_, _, err = stale.GetProof(big.NewInt(100))
+	assert.ErrorIs(err, zk.ErrFingerprintMismatch)
+	assignment, _, err := stale.Assignment(big.NewInt(100))
+	assert.NoError(err)
+	_, _, err = pool.Prove(context.Background(), 0, assignment, stale.ProveOptions()...)
+	assert.ErrorIs(err, zk.ErrFingerprintMismatch)
+
+	other, err := zk.NewBiddingWithProver(g16)
+	assert.NoError(err)
+	assert.NoError(other.InitSession(1111, "username_2", big.NewInt(1001)))
+	_, _, err = other.GetProof(big.NewInt(100))
+	assert.NoError(err)
+}
+
+func TestProverPoolPriority(t *testing.T) {
+	assert := test.NewAssert(t)
+	f := newBatchFixture(t, 0)
+	pool, err := zk.NewProverPool(f.g16, zk.PoolConfig{Workers: 1, MaxQueue: 3})
+	assert.NoError(err)
+
+	assignment := func(v int64) frontend.Circuit {
+		return &zk_circuit.PrivateValueCircuit{PrivateValue: v, Hash: zk_circuit.HashMIMC(big.NewInt(v).Bytes())}
+	}
+
+	// hold the only worker until the queue is filled
+	started, release := make(chan struct{}), make(chan struct{})
+	first, err := pool.Submit(context.Background(), 0, assignment(1), zk.WithProgress(func(p zk.ProofProgress) {
+		if p.Phase == zk.PhaseWitness && !p.Done {
+			close(started)
+			<-release
+		}
+	}))
+	assert.NoError(err)
+	<-started
+
+	var mu sync.Mutex
+	var order []int64
+	record := func(v int64) zk.ProveOption {
+		return zk.WithProgress(func(p zk.ProofProgress) {
+			if p.Phase == zk.PhaseWitness && !p.Done {
+				mu.Lock()
+				order = append(order, v)
+				mu.Unlock()
+			}
+		})
+	}
+	low, err := pool.Submit(context.Background(), 0, assignment(2), record(2))
+	assert.NoError(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	dropped, err := pool.Submit(ctx, 5, assignment(3), record(3))
+	assert.NoError(err)
+	high, err := pool.Submit(context.Background(), 10, assignment(4), record(4))
+	assert.NoError(err)
+	_, err = pool.Submit(context.Background(), 0, assignment(5))
+	assert.ErrorIs(err, zk.ErrPoolFull)
+	assert.Equal(3, pool.Queued())
+
+	cancel()
+	close(release)
+	for _, done := range []<-chan zk.ProofResult{first, high, low} {
+		assert.NoError((<-done).Err)
+	}
+	assert.ErrorIs((<-dropped).Err, context.Canceled, "a cancelled job should not be proven")
+	assert.Equal([]int64{4, 2}, order, "jobs should run by priority")
+
+	pool.Close()
+	_, err = pool.Submit(context.Background(), 0, assignment(6))
+	assert.ErrorIs(err, zk.ErrPoolClosed)
+}
+
+func TestProverPoolMemory(t *testing.T) {
+	assert := test.NewAssert(t)
+	f := newBatchFixture(t, 0)
+	perProof, err := f.g16.ProofMemory()
+	assert.NoError(err)
+
+	pool, err := zk.NewProverPool(f.g16, zk.PoolConfig{Workers: 8, MaxMemory: 2*perProof + 1})
+	assert.NoError(err)
+	assert.Equal(2, pool.Workers(), "workers should be bounded by the memory")
+	pool.Close()
+
+	_, err = zk.NewProverPool(f.g16, zk.PoolConfig{MaxMemory: perProof - 1})
+	assert.Error(err, "one proof should fit in the memory")
+}
@@ -0,0 +1,732 @@
+package zk
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/compiled"
+	"io"
+	"math/big"
+	"math/bits"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// PowersOfTauVersion is the version of the phase-1 transcript format
+const PowersOfTauVersion = 1
+
+// maxPowersOfTauPower is the 2-adicity of fr: the FFT domains have at most 2^28 elements
+const maxPowersOfTauPower = 28
+
+const powersOfTauDST = "GNARK-BID-PHASE1-V1_BN254G2_XMD:SHA-256_SVDW_RO_"
+
+// PowersOfTau is the transcript of a multi-party phase-1 setup, the powers of tau of Bowe, Gabizon, Miers
+// (https://eprint.iacr.org/2017/1050).
+//
+// The accumulator holds the powers of τ and their products with α and β. Each participant multiplies them by its own
+// secrets and forgets them: τ, α and β are unknown as long as one participant is honest. The transcript is not bound
+// to a circuit, NewCeremony starts the phase 2 of any circuit of at most 2^Power constraints from it.
+type PowersOfTau struct {
+	Version       int                       `json:"version"`
+	Power         int                       `json:"power"`
+	Contributions []PowersOfTauContribution `json:"contributions"`
+	Current       PowersOfTauParameters     `json:"current"`
+}
+
+// PowersOfTauParameters is the accumulator, compressed points concatenated, with n = 2^Power
+type PowersOfTauParameters struct {
+	TauG1      []byte `json:"tauG1"`      // [τ^i]1, i < 2n
+	TauG2      []byte `json:"tauG2"`      // [τ^i]2, i < n
+	AlphaTauG1 []byte `json:"alphaTauG1"` // [α·τ^i]1, i < n
+	BetaTauG1  []byte `json:"betaTauG1"`  // [β·τ^i]1, i < n
+	BetaG2     []byte `json:"betaG2"`     // [β]2
+}
+
+// PowersOfTauContribution is the public part of a contribution: [τ]1, [α]1 and [β]1 after the contribution and the
+// proofs of knowledge of the secrets they were multiplied by
+type PowersOfTauContribution struct {
+	Name      string         `json:"name"`
+	Challenge []byte         `json:"challenge"`
+	TauG1     []byte         `json:"tauG1"`
+	AlphaG1   []byte         `json:"alphaG1"`
+	BetaG1    []byte         `json:"betaG1"`
+	Tau       KnowledgeProof `json:"tau"`
+	Alpha     KnowledgeProof `json:"alpha"`
+	Beta      KnowledgeProof `json:"beta"`
+}
+
+// KnowledgeProof proves the knowledge of the x a point was multiplied by: r = hashToG2(challenge ∥ i ∥ s ∥ s·x) where
+// i is the index of the secret in the contribution, and the verifier checks e(s, r·x) = e(s·x, r) and
+// e(next, r) = e(previous, r·x)
+type KnowledgeProof struct {
+	S  []byte `json:"s"`  // [s]1
+	SX []byte `json:"sx"` // [s·x]1
+	RX []byte `json:"rx"` // [r·x]2
+}
+
+// powersOfTau is the decoded accumulator
+type powersOfTau struct {
+	tauG1, alphaTauG1, betaTauG1 []bn254.G1Affine
+	tauG2                        []bn254.G2Affine
+	betaG2                       bn254.G2Affine
+}
+
+// NewPowersOfTau returns the transcript of a phase-1 setup for circuits of at most 2^power constraints, without
+// contributions: all the powers are the generators
+func NewPowersOfTau(power int) (*PowersOfTau, error) {
+	if power < 1 || power > maxPowersOfTauPower {
+		return nil, fmt.Errorf("powers of tau: power %d out of [1, %d]", power, maxPowersOfTauPower)
+	}
+	n := 1 << power
+	_, _, g1, g2 := bn254.Generators()
+	acc := powersOfTau{
+		tauG1:      make([]bn254.G1Affine, 2*n),
+		alphaTauG1: make([]bn254.G1Affine, n),
+		betaTauG1:  make([]bn254.G1Affine, n),
+		tauG2:      make([]bn254.G2Affine, n),
+		betaG2:     g2,
+	}
+	for _, points := range [][]bn254.G1Affine{acc.tauG1, acc.alphaTauG1, acc.betaTauG1} {
+		for i := range points {
+			points[i] = g1
+		}
+	}
+	for i := range acc.tauG2 {
+		acc.tauG2[i] = g2
+	}
+	return &PowersOfTau{
+		Version: PowersOfTauVersion,
+		Power:   power,
+		Current: acc.encode(),
+	}, nil
+}
+
+// ReadPowersOfTau reads a phase-1 transcript file
+func ReadPowersOfTau(fileName string) (*PowersOfTau, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var p PowersOfTau
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	if p.Version != PowersOfTauVersion {
+		return nil, fmt.Errorf("unsupported powers of tau version %d, want %d", p.Version, PowersOfTauVersion)
+	}
+	return &p, nil
+}
+
+// WriteFile writes the transcript to a file, to be passed to the next participant
+func (p *PowersOfTau) WriteFile(fileName string) error {
+	jsonBytes, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, jsonBytes, 0o644)
+}
+
+// Contribute multiplies τ, α and β by secrets read from random (crypto/rand.Reader if nil)
+func (p *PowersOfTau) Contribute(name string, random io.Reader) error {
+	if random == nil {
+		random = rand.Reader
+	}
+	acc, err := p.Current.decode(p.Power)
+	if err != nil {
+		return err
+	}
+
+	var secrets [3]*big.Int
+	for i := range secrets {
+		if secrets[i], err = randomScalar(random); err != nil {
+			return err
+		}
+	}
+	challenge := p.challenge(len(p.Contributions))
+	contribution := PowersOfTauContribution{Name: name, Challenge: challenge}
+	for i, proof := range []*KnowledgeProof{&contribution.Tau, &contribution.Alpha, &contribution.Beta} {
+		if *proof, err = proveKnowledge(challenge, byte(i), secrets[i], random); err != nil {
+			return err
+		}
+	}
+
+	acc.multiply(secrets[0], secrets[1], secrets[2])
+	tau, alpha, beta := acc.tauG1[1].Bytes(), acc.alphaTauG1[0].Bytes(), acc.betaTauG1[0].Bytes()
+	contribution.TauG1, contribution.AlphaG1, contribution.BetaG1 = tau[:], alpha[:], beta[:]
+	p.Contributions = append(p.Contributions, contribution)
+	p.Current = acc.encode()
+
+	// the secrets are not stored anywhere else
+	for _, x := range secrets {
+		x.SetUint64(0)
+	}
+	return nil
+}
+
+// Verify checks the whole transcript: the proofs of knowledge, the chain of [τ]1, [α]1, [β]1 and that the accumulator
+// holds the powers of the last ones
+func (p *PowersOfTau) Verify() error {
+	if p.Power < 1 || p.Power > maxPowersOfTauPower {
+		return fmt.Errorf("%w: power %d out of [1, %d]", ErrInvalidContribution, p.Power, maxPowersOfTauPower)
+	}
+	acc, err := p.Current.decode(p.Power)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidContribution, err)
+	}
+
+	_, _, g1, g2 := bn254.Generators()
+	previous := [3]bn254.G1Affine{g1, g1, g1}
+	for i, contribution := range p.Contributions {
+		if !bytes.Equal(contribution.Challenge, p.challenge(i)) {
+			return fmt.Errorf("%w %d (%s): wrong challenge", ErrInvalidContribution, i, contribution.Name)
+		}
+		var next [3]bn254.G1Affine
+		for k, b := range [][]byte{contribution.TauG1, contribution.AlphaG1, contribution.BetaG1} {
+			if _, err := next[k].SetBytes(b); err != nil {
+				return fmt.Errorf("%w %d (%s): %v", ErrInvalidContribution, i, contribution.Name, err)
+			}
+		}
+		for k, proof := range []KnowledgeProof{contribution.Tau, contribution.Alpha, contribution.Beta} {
+			if err := proof.verify(contribution.Challenge, byte(k), &previous[k], &next[k]); err != nil {
+				return fmt.Errorf("%w %d (%s): %v", ErrInvalidContribution, i, contribution.Name, err)
+			}
+		}
+		previous = next
+	}
+
+	if !acc.tauG1[0].Equal(&g1) || !acc.tauG2[0].Equal(&g2) {
+		return fmt.Errorf("%w: the first powers are not the generators", ErrInvalidContribution)
+	}
+	if !acc.tauG1[1].Equal(&previous[0]) || !acc.alphaTauG1[0].Equal(&previous[1]) || !acc.betaTauG1[0].Equal(&previous[2]) {
+		return fmt.Errorf("%w: [τ]1, [α]1, [β]1 are not the ones of the last contribution", ErrInvalidContribution)
+	}
+	return acc.checkPowers()
+}
+
+// Hash identifies the transcript, its contributions and its accumulator
+func (p *PowersOfTau) Hash() string {
+	h := sha256.New()
+	h.Write(p.challenge(len(p.Contributions)))
+	for _, b := range [][]byte{p.Current.TauG1, p.Current.TauG2, p.Current.AlphaTauG1, p.Current.BetaTauG1, p.Current.BetaG2} {
+		h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// challenge binds a contribution to all the previous ones
+func (p *PowersOfTau) challenge(i int) []byte {
+	h := sha256.New()
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(p.Version))
+	h.Write(n[:])
+	binary.BigEndian.PutUint64(n[:], uint64(p.Power))
+	h.Write(n[:])
+	for _, contribution := range p.Contributions[:i] {
+		binary.BigEndian.PutUint64(n[:], uint64(len(contribution.Name)))
+		h.Write(n[:])
+		h.Write([]byte(contribution.Name))
+		h.Write(contribution.TauG1)
+		h.Write(contribution.AlphaG1)
+		h.Write(contribution.BetaG1)
+		for _, proof := range []KnowledgeProof{contribution.Tau, contribution.Alpha, contribution.Beta} {
+			h.Write(proof.S)
+			h.Write(proof.SX)
+			h.Write(proof.RX)
+		}
+	}
+	return h.Sum(nil)
+}
+
+// groth16Keys returns the keys the Setup of gnark computes for r1cs, with the τ, α, β of the accumulator instead of
+// sampled secrets and γ = δ = 1: the phase-2 contributions of a Ceremony randomize δ. The keys are only meant to be
+// serialized, the precomputed values of the verifying key are set when it is read back.
+func (p *PowersOfTau) groth16Keys(r1cs frontend.CompiledConstraintSystem) (groth16.ProvingKey, groth16.VerifyingKey, error) {
+	constraints, r1csCoefficients, err := r1csBN254(r1cs)
+	if err != nil {
+		return nil, nil, err
+	}
+	internal, secret, public := r1cs.GetNbVariables()
+	nbWires := internal + secret + public
+
+	domain := fft.NewDomain(uint64(len(constraints)))
+	n := int(domain.Cardinality)
+	if n > 1<<p.Power {
+		return nil, nil, fmt.Errorf("%d constraints need 2^%d powers of tau, the transcript has 2^%d",
+			len(constraints), bits.TrailingZeros(uint(n)), p.Power)
+	}
+	acc, err := p.Current.decode(p.Power)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// [Lⱼ(τ)]1, [α·Lⱼ(τ)]1, [β·Lⱼ(τ)]1 and [Lⱼ(τ)]2 of the Lagrange basis of the domain
+	l1 := lagrangeBasis(g1Jacobians(acc.tauG1[:n]), domain)
+	alphaL1 := lagrangeBasis(g1Jacobians(acc.alphaTauG1[:n]), domain)
+	betaL1 := lagrangeBasis(g1Jacobians(acc.betaTauG1[:n]), domain)
+	l2 := lagrangeBasis(g2Jacobians(acc.tauG2[:n]), domain)
+
+	// [Aᵢ(τ)]1, [Bᵢ(τ)]1, [Bᵢ(τ)]2 and [β·Aᵢ(τ) + α·Bᵢ(τ) + Cᵢ(τ)]1 of each wire
+	coefficients := make([]big.Int, len(r1csCoefficients))
+	for i, c := range r1csCoefficients {
+		c.ToBigIntRegular(&coefficients[i])
+	}
+	a1, b1, k1 := make([]bn254.G1Jac, nbWires), make([]bn254.G1Jac, nbWires), make([]bn254.G1Jac, nbWires)
+	b2 := make([]bn254.G2Jac, nbWires)
+	for j, c := range constraints {
+		for _, t := range c.L {
+			addTerm(&a1[t.WireID()], t.CoeffID(), coefficients, &l1[j])
+			addTerm(&k1[t.WireID()], t.CoeffID(), coefficients, &betaL1[j])
+		}
+		for _, t := range c.R {
+			addTerm(&b1[t.WireID()], t.CoeffID(), coefficients, &l1[j])
+			addTerm(&b2[t.WireID()], t.CoeffID(), coefficients, &l2[j])
+			addTerm(&k1[t.WireID()], t.CoeffID(), coefficients, &alphaL1[j])
+		}
+		for _, t := range c.O {
+			addTerm(&k1[t.WireID()], t.CoeffID(), coefficients, &l1[j])
+		}
+	}
+
+	// [τ^i·(τ^n - 1)]1, i < n, bit-reversed as in the Setup of gnark
+	z := make([]bn254.G1Jac, n)
+	for i := range z {
+		var q bn254.G1Jac
+		z[i].FromAffine(&acc.tauG1[i+n])
+		q.FromAffine(&acc.tauG1[i])
+		z[i].SubAssign(&q)
+	}
+	bitReverse(z)
+
+	pk := groth16.NewProvingKey(ecc.BN254)
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	_pk, err := provingKeyBN254(pk)
+	if err != nil {
+		return nil, nil, err
+	}
+	_vk, err := verifyingKeyBN254(vk)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, _, g1, g2 := bn254.Generators()
+
+	// the points at infinity of A and B are skipped, B is at infinity in G1 and G2 for the same wires
+	a, b, bG2 := g1Affines(a1), g1Affines(b1), g2Affines(b2)
+	*_pk.InfinityA, *_pk.InfinityB = make([]bool, nbWires), make([]bool, nbWires)
+	*_pk.G1A, *_pk.G1B, *_pk.G2B = nil, nil, nil
+	for i := 0; i < nbWires; i++ {
+		if a[i].IsInfinity() {
+			(*_pk.InfinityA)[i] = true
+			*_pk.NbInfinityA++
+		} else {
+			*_pk.G1A = append(*_pk.G1A, a[i])
+		}
+		if b[i].IsInfinity() {
+			(*_pk.InfinityB)[i] = true
+			*_pk.NbInfinityB++
+		} else {
+			*_pk.G1B = append(*_pk.G1B, b[i])
+			*_pk.G2B = append(*_pk.G2B, bG2[i])
+		}
+	}
+	k := g1Affines(k1)
+
+	*_pk.Domain = *domain
+	*_pk.G1Alpha, *_pk.G1Beta, *_pk.G1Delta = acc.alphaTauG1[0], acc.betaTauG1[0], g1
+	*_pk.G1K = k[public:]
+	*_pk.G1Z = g1Affines(z)
+	*_pk.G2Beta, *_pk.G2Delta = acc.betaG2, g2
+
+	*_vk.G1Alpha, *_vk.G1Beta, *_vk.G1Delta = acc.alphaTauG1[0], acc.betaTauG1[0], g1
+	*_vk.G1K = k[:public]
+	*_vk.G2Beta, *_vk.G2Delta, *_vk.G2Gamma = acc.betaG2, g2, g2
+	return pk, vk, nil
+}
+
+// multiply multiplies τ, α and β of the accumulator by secrets
+func (acc *powersOfTau) multiply(tau, alpha, beta *big.Int) {
+	var t, a, b fr.Element
+	t.SetBigInt(tau)
+	a.SetBigInt(alpha)
+	b.SetBigInt(beta)
+	powers := make([]fr.Element, len(acc.tauG1))
+	powers[0].SetOne()
+	for i := 1; i < len(powers); i++ {
+		powers[i].Mul(&powers[i-1], &t)
+	}
+
+	n := len(acc.tauG2)
+	parallel(len(powers), func(start, end int) {
+		var s big.Int
+		var e fr.Element
+		for i := start; i < end; i++ {
+			powers[i].ToBigIntRegular(&s)
+			acc.tauG1[i].ScalarMultiplication(&acc.tauG1[i], &s)
+			if i >= n {
+				continue
+			}
+			acc.tauG2[i].ScalarMultiplication(&acc.tauG2[i], &s)
+			e.Mul(&powers[i], &a).ToBigIntRegular(&s)
+			acc.alphaTauG1[i].ScalarMultiplication(&acc.alphaTauG1[i], &s)
+			e.Mul(&powers[i], &b).ToBigIntRegular(&s)
+			acc.betaTauG1[i].ScalarMultiplication(&acc.betaTauG1[i], &s)
+		}
+	})
+	acc.betaG2.ScalarMultiplication(&acc.betaG2, beta)
+
+	for i := range powers {
+		powers[i].SetZero()
+	}
+	t.SetZero()
+	a.SetZero()
+	b.SetZero()
+}
+
+// checkPowers checks the structure of the accumulator with random linear combinations of the consecutive powers:
+// e(Σρᵢ[τ^(i+1)]1, [1]2) = e(Σρᵢ[τ^i]1, [τ]2), the same for α·τ^i and β·τ^i, e([τ]1, Σρᵢ[τ^i]2) = e([1]1, Σρᵢ[τ^(i+1)]2),
+// e([τ]1, [1]2) = e([1]1, [τ]2) and e([β]1, [1]2) = e([1]1, [β]2)
+func (acc *powersOfTau) checkPowers() error {
+	_, _, g1, g2 := bn254.Generators()
+	var g1Neg bn254.G1Affine
+	g1Neg.Neg(&g1)
+	tauG2 := acc.tauG2[1]
+
+	for _, powers := range [][]bn254.G1Affine{acc.tauG1, acc.alphaTauG1, acc.betaTauG1} {
+		rho, err := randomElements(len(powers) - 1)
+		if err != nil {
+			return err
+		}
+		var lo, hi bn254.G1Affine
+		if _, err := lo.MultiExp(powers[:len(powers)-1], rho, ecc.MultiExpConfig{}); err != nil {
+			return err
+		}
+		if _, err := hi.MultiExp(powers[1:], rho, ecc.MultiExpConfig{}); err != nil {
+			return err
+		}
+		lo.Neg(&lo)
+		if ok, err := bn254.PairingCheck([]bn254.G1Affine{hi, lo}, []bn254.G2Affine{g2, tauG2}); err != nil || !ok {
+			return fmt.Errorf("%w: the G1 powers are not consecutive powers of τ", ErrInvalidContribution)
+		}
+	}
+
+	rho, err := randomElements(len(acc.tauG2) - 1)
+	if err != nil {
+		return err
+	}
+	var lo, hi bn254.G2Affine
+	if _, err := lo.MultiExp(acc.tauG2[:len(acc.tauG2)-1], rho, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	if _, err := hi.MultiExp(acc.tauG2[1:], rho, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	if ok, err := bn254.PairingCheck([]bn254.G1Affine{acc.tauG1[1], g1Neg}, []bn254.G2Affine{lo, hi}); err != nil || !ok {
+		return fmt.Errorf("%w: the G2 powers are not consecutive powers of τ", ErrInvalidContribution)
+	}
+	if ok, err := bn254.PairingCheck([]bn254.G1Affine{acc.tauG1[1], g1Neg}, []bn254.G2Affine{g2, tauG2}); err != nil || !ok {
+		return fmt.Errorf("%w: [τ]1 and [τ]2 differ", ErrInvalidContribution)
+	}
+	if ok, err := bn254.PairingCheck([]bn254.G1Affine{acc.betaTauG1[0], g1Neg}, []bn254.G2Affine{g2, acc.betaG2}); err != nil || !ok {
+		return fmt.Errorf("%w: [β]1 and [β]2 differ", ErrInvalidContribution)
+	}
+	return nil
+}
+
+func (acc *powersOfTau) encode() PowersOfTauParameters {
+	betaG2 := acc.betaG2.Bytes()
+	return PowersOfTauParameters{
+		TauG1:      encodeG1(acc.tauG1),
+		TauG2:      encodeG2(acc.tauG2),
+		AlphaTauG1: encodeG1(acc.alphaTauG1),
+		BetaTauG1:  encodeG1(acc.betaTauG1),
+		BetaG2:     betaG2[:],
+	}
+}
+
+func (p PowersOfTauParameters) decode(power int) (*powersOfTau, error) {
+	n := 1 << power
+	var acc powersOfTau
+	var err error
+	if acc.tauG1, err = decodeG1(p.TauG1, 2*n); err != nil {
+		return nil, err
+	}
+	if acc.alphaTauG1, err = decodeG1(p.AlphaTauG1, n); err != nil {
+		return nil, err
+	}
+	if acc.betaTauG1, err = decodeG1(p.BetaTauG1, n); err != nil {
+		return nil, err
+	}
+	if acc.tauG2, err = decodeG2(p.TauG2, n); err != nil {
+		return nil, err
+	}
+	if _, err := acc.betaG2.SetBytes(p.BetaG2); err != nil {
+		return nil, err
+	}
+	return &acc, nil
+}
+
+func encodeG1(points []bn254.G1Affine) []byte {
+	buf := make([]byte, 0, len(points)*bn254.SizeOfG1AffineCompressed)
+	for i := range points {
+		b := points[i].Bytes()
+		buf = append(buf, b[:]...)
+	}
+	return buf
+}
+
+func encodeG2(points []bn254.G2Affine) []byte {
+	buf := make([]byte, 0, len(points)*bn254.SizeOfG2AffineCompressed)
+	for i := range points {
+		b := points[i].Bytes()
+		buf = append(buf, b[:]...)
+	}
+	return buf
+}
+
+func decodeG1(data []byte, n int) ([]bn254.G1Affine, error) {
+	if len(data) != n*bn254.SizeOfG1AffineCompressed {
+		return nil, fmt.Errorf("%d bytes for %d G1 points", len(data), n)
+	}
+	points := make([]bn254.G1Affine, n)
+	errs := make([]error, n)
+	parallel(n, func(start, end int) {
+		for i := start; i < end; i++ {
+			_, errs[i] = points[i].SetBytes(data[i*bn254.SizeOfG1AffineCompressed:])
+		}
+	})
+	return points, firstError(errs)
+}
+
+func decodeG2(data []byte, n int) ([]bn254.G2Affine, error) {
+	if len(data) != n*bn254.SizeOfG2AffineCompressed {
+		return nil, fmt.Errorf("%d bytes for %d G2 points", len(data), n)
+	}
+	points := make([]bn254.G2Affine, n)
+	errs := make([]error, n)
+	parallel(n, func(start, end int) {
+		for i := start; i < end; i++ {
+			_, errs[i] = points[i].SetBytes(data[i*bn254.SizeOfG2AffineCompressed:])
+		}
+	})
+	return points, firstError(errs)
+}
+
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func proveKnowledge(challenge []byte, i byte, x *big.Int, random io.Reader) (KnowledgeProof, error) {
+	s, err := randomScalar(random)
+	if err != nil {
+		return KnowledgeProof{}, err
+	}
+	_, _, g1, _ := bn254.Generators()
+	var sG1, sxG1 bn254.G1Affine
+	sG1.ScalarMultiplication(&g1, s)
+	sxG1.ScalarMultiplication(&sG1, x)
+	s.SetUint64(0)
+	r, err := knowledgeR(challenge, i, &sG1, &sxG1)
+	if err != nil {
+		return KnowledgeProof{}, err
+	}
+	var rxG2 bn254.G2Affine
+	rxG2.ScalarMultiplication(&r, x)
+	sBytes, sxBytes, rxBytes := sG1.Bytes(), sxG1.Bytes(), rxG2.Bytes()
+	return KnowledgeProof{S: sBytes[:], SX: sxBytes[:], RX: rxBytes[:]}, nil
+}
+
+// verify checks the proof and that next is previous multiplied by the same x
+func (k KnowledgeProof) verify(challenge []byte, i byte, previous, next *bn254.G1Affine) error {
+	var s, sx bn254.G1Affine
+	var rx bn254.G2Affine
+	if _, err := s.SetBytes(k.S); err != nil {
+		return err
+	}
+	if _, err := sx.SetBytes(k.SX); err != nil {
+		return err
+	}
+	if _, err := rx.SetBytes(k.RX); err != nil {
+		return err
+	}
+	if next.IsInfinity() || s.IsInfinity() || sx.IsInfinity() || rx.IsInfinity() {
+		return fmt.Errorf("point at infinity")
+	}
+	r, err := knowledgeR(challenge, i, &s, &sx)
+	if err != nil {
+		return err
+	}
+	var sxNeg, previousNeg bn254.G1Affine
+	sxNeg.Neg(&sx)
+	previousNeg.Neg(previous)
+	if ok, err := bn254.PairingCheck([]bn254.G1Affine{s, sxNeg}, []bn254.G2Affine{rx, r}); err != nil || !ok {
+		return fmt.Errorf("invalid proof of knowledge of secret %d", i)
+	}
+	if ok, err := bn254.PairingCheck([]bn254.G1Affine{*next, previousNeg}, []bn254.G2Affine{r, rx}); err != nil || !ok {
+		return fmt.Errorf("secret %d: the point is not the previous one multiplied by x", i)
+	}
+	return nil
+}
+
+func knowledgeR(challenge []byte, i byte, s, sx *bn254.G1Affine) (bn254.G2Affine, error) {
+	sBytes, sxBytes := s.Bytes(), sx.Bytes()
+	msg := append(append(append(append([]byte{}, challenge...), i), sBytes[:]...), sxBytes[:]...)
+	return bn254.HashToCurveG2Svdw(msg, []byte(powersOfTauDST))
+}
+
+func randomElements(n int) ([]fr.Element, error) {
+	rho := make([]fr.Element, n)
+	for i := range rho {
+		if _, err := rho[i].SetRandom(); err != nil {
+			return nil, err
+		}
+	}
+	return rho, nil
+}
+
+// jacobian is bn254.G1Jac or bn254.G2Jac
+type jacobian[T any] interface {
+	*T
+	Set(*T) *T
+	AddAssign(*T) *T
+	SubAssign(*T) *T
+	ScalarMultiplication(*T, *big.Int) *T
+}
+
+// lagrangeBasis returns [Lⱼ(τ)] = 1/n·Σₖ ω^(-jk)·[τ^k], j < n, the inverse FFT of the powers [τ^k], k < n, on the domain
+func lagrangeBasis[T any, P jacobian[T]](powers []T, domain *fft.Domain) []T {
+	n := len(powers)
+	a := append([]T{}, powers...)
+	bitReverse(a)
+	for m := 2; m <= n; m <<= 1 {
+		half := m / 2
+		var wm, w fr.Element
+		wm.Exp(domain.GeneratorInv, big.NewInt(int64(n/m)))
+		w.SetOne()
+		twiddles := make([]big.Int, half)
+		for j := range twiddles {
+			w.ToBigIntRegular(&twiddles[j])
+			w.Mul(&w, &wm)
+		}
+		parallel(n/2, func(start, end int) {
+			var t T
+			for butterfly := start; butterfly < end; butterfly++ {
+				j := butterfly % half
+				lo := butterfly/half*m + j
+				hi := lo + half
+				P(&t).ScalarMultiplication(&a[hi], &twiddles[j])
+				a[hi] = a[lo]
+				P(&a[hi]).SubAssign(&t)
+				P(&a[lo]).AddAssign(&t)
+			}
+		})
+	}
+	var nInv big.Int
+	domain.CardinalityInv.ToBigIntRegular(&nInv)
+	parallel(n, func(start, end int) {
+		for i := start; i < end; i++ {
+			P(&a[i]).ScalarMultiplication(&a[i], &nInv)
+		}
+	})
+	return a
+}
+
+// addTerm adds the term of a linear expression of the R1CS applied to p to res
+func addTerm[T any, P jacobian[T]](res *T, coeffID int, coefficients []big.Int, p *T) {
+	switch coeffID {
+	case compiled.CoeffIdZero:
+	case compiled.CoeffIdOne:
+		P(res).AddAssign(p)
+	case compiled.CoeffIdMinusOne:
+		P(res).SubAssign(p)
+	case compiled.CoeffIdTwo:
+		P(res).AddAssign(p)
+		P(res).AddAssign(p)
+	default:
+		var t T
+		P(&t).ScalarMultiplication(p, &coefficients[coeffID])
+		P(res).AddAssign(&t)
+	}
+}
+
+func bitReverse[T any](a []T) {
+	n := uint(len(a))
+	nn := uint(bits.UintSize - bits.TrailingZeros(n))
+	for i := uint(0); i < n; i++ {
+		irev := bits.Reverse(i) >> nn
+		if irev > i {
+			a[i], a[irev] = a[irev], a[i]
+		}
+	}
+}
+
+func g1Jacobians(points []bn254.G1Affine) []bn254.G1Jac {
+	res := make([]bn254.G1Jac, len(points))
+	for i := range points {
+		res[i].FromAffine(&points[i])
+	}
+	return res
+}
+
+func g2Jacobians(points []bn254.G2Affine) []bn254.G2Jac {
+	res := make([]bn254.G2Jac, len(points))
+	for i := range points {
+		res[i].FromAffine(&points[i])
+	}
+	return res
+}
+
+func g1Affines(points []bn254.G1Jac) []bn254.G1Affine {
+	res := make([]bn254.G1Affine, len(points))
+	bn254.BatchJacobianToAffineG1(points, res)
+	return res
+}
+
+func g2Affines(points []bn254.G2Jac) []bn254.G2Affine {
+	res := make([]bn254.G2Affine, len(points))
+	parallel(len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			res[i].FromJacobian(&points[i])
+		}
+	})
+	return res
+}
+
+// parallel splits [0, n) in one chunk per CPU
+func parallel(n int, work func(start, end int)) {
+	nbTasks := runtime.NumCPU()
+	if nbTasks > n {
+		nbTasks = n
+	}
+	if nbTasks == 0 {
+		return
+	}
+	chunk := (n + nbTasks - 1) / nbTasks
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += chunk {
+		end := start + chunk
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			work(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,76 @@
+package zk_test
+
+import (
+	"errors"
+	"github.com/consensys/gnark/test"
+	"gnark-bid/zk"
+	"path/filepath"
+	"testing"
+)
+
+func TestPowersOfTau(t *testing.T) {
+	assert := test.NewAssert(t)
+	dir := t.TempDir()
+
+	_, err := zk.NewPowersOfTau(0)
+	assert.Error(err, "power 0 should fail")
+
+	p, err := zk.NewPowersOfTau(4)
+	assert.NoError(err)
+	assert.NoError(p.Verify(), "the generators should verify")
+
+	// participants only exchange files
+	in := filepath.Join(dir, "pot.json")
+	assert.NoError(p.WriteFile(in))
+	for _, name := range []string{"alice", "bob"} {
+		p, err = zk.ReadPowersOfTau(in)
+		assert.NoError(err, "reading transcript failed")
+		assert.NoError(p.Verify(), "transcript should verify")
+		assert.NoError(p.Contribute(name, nil), "contribution failed")
+		assert.NoError(p.WriteFile(in))
+	}
+	p, err = zk.ReadPowersOfTau(in)
+	assert.NoError(err)
+	assert.Equal(2, len(p.Contributions))
+	assert.NoError(p.Verify(), "full chain should verify")
+
+	// the proof of knowledge doesn't match another challenge
+	tampered := *p
+	tampered.Contributions = append([]zk.PowersOfTauContribution{}, p.Contributions...)
+	tampered.Contributions[0].Name = "mallory"
+	assert.True(errors.Is(tampered.Verify(), zk.ErrInvalidContribution), "renamed contribution should fail")
+
+	// a contribution can't be dropped
+	tampered = *p
+	tampered.Contributions = p.Contributions[:1]
+	assert.True(errors.Is(tampered.Verify(), zk.ErrInvalidContribution), "missing contribution should fail")
+
+	// the accumulator holds consecutive powers
+	tampered = *p
+	tampered.Current.TauG1 = append([]byte{}, p.Current.TauG1...)
+	copy(tampered.Current.TauG1[3*32:4*32], p.Current.TauG1[5*32:6*32])
+	assert.True(errors.Is(tampered.Verify(), zk.ErrInvalidContribution), "swapped powers should fail")
+	tampered = *p
+	tampered.Current.BetaG2 = p.Current.TauG2[64:128]
+	assert.True(errors.Is(tampered.Verify(), zk.ErrInvalidContribution), "[β]2 should match [β]1")
+
+	// the accumulator is the one of the last contribution
+	other := newPowersOfTau(t, 4, "mallory")
+	tampered = *p
+	tampered.Current = other.Current
+	assert.True(errors.Is(tampered.Verify(), zk.ErrInvalidContribution), "another accumulator should fail")
+	assert.NotEqual(p.Hash(), other.Hash())
+}
+
+func newPowersOfTau(t *testing.T, power int, names ...string) *zk.PowersOfTau {
+	p, err := zk.NewPowersOfTau(power)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if err := p.Contribute(name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return p
+}
@@ -0,0 +1,154 @@
+package zk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/ethereum/go-ethereum/common"
+	"gnark-bid/telemetry"
+	"time"
+)
+
+// ProofPhase is a step of the proof generation
+type ProofPhase string
+
+const (
+	PhaseWitness ProofPhase = "witness" // building the witness from the assignment
+	PhaseProve   ProofPhase = "prove"   // groth16.Prove: solving the constraint system, the quotient H (FFTs) and the MSMs
+	PhaseVerify  ProofPhase = "verify"  // self-verification of the proof
+)
+
+// ProofProgress is reported when a phase starts and when it ends (Done, with its duration). groth16.Prove of gnark
+// v0.7.1 has no hooks, so the solver, the FFTs and the MSMs are reported as one PhaseProve.
+type ProofProgress struct {
+	Phase   ProofPhase
+	Done    bool
+	Elapsed time.Duration
+}
+
+// ProveOption configures GenerateProof
+type ProveOption func(*proveConfig)
+
+type proveConfig struct {
+	circuit        string
+	progress       func(ProofProgress)
+	skipSelfVerify bool
+	proverOptions  []backend.ProverOption
+	verifierDigest *common.Hash
+	proverDone     *<-chan struct{}
+}
+
+// WithProgress calls fn at the start and the end of each phase, from the proving goroutine
+func WithProgress(fn func(ProofProgress)) ProveOption {
+	return func(c *proveConfig) {
+		c.progress = fn
+	}
+}
+
+// WithProgressChan sends the progress to ch, events are dropped when ch is full so proving never blocks on it
+func WithProgressChan(ch chan<- ProofProgress) ProveOption {
+	return WithProgress(func(p ProofProgress) {
+		select {
+		case ch <- p:
+		default:
+		}
+	})
+}
+
+// WithoutSelfVerify skips the verification of the proof after proving
+func WithoutSelfVerify() ProveOption {
+	return func(c *proveConfig) {
+		c.skipSelfVerify = true
+	}
+}
+
+// WithProverOptions passes options to groth16.Prove, e.g. backend.IgnoreSolverError or backend.WithHints
+func WithProverOptions(opts ...backend.ProverOption) ProveOption {
+	return func(c *proveConfig) {
+		c.proverOptions = append(c.proverOptions, opts...)
+	}
+}
+
+// WithVerifierFingerprint refuses to prove, with ErrFingerprintMismatch, when digest isn't the fingerprint digest of the
+// keys: the proof would not verify with the verifier of digest
+func WithVerifierFingerprint(digest common.Hash) ProveOption {
+	return func(c *proveConfig) {
+		c.verifierDigest = &digest
+	}
+}
+
+// withProverDone sets *done to a channel closed when groth16.Prove returns, which may be after GenerateProof returned
+// ctx.Err(), e.g. for the pool to keep its memory bound. *done stays nil when Prove didn't start.
+func withProverDone(done *<-chan struct{}) ProveOption {
+	return func(c *proveConfig) {
+		c.proverDone = done
+	}
+}
+
+// run reports the phase and runs fn if ctx is not done yet
+func (c *proveConfig) run(ctx context.Context, phase ProofPhase, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	start := time.Now()
+	if c.progress != nil {
+		c.progress(ProofProgress{Phase: phase})
+	}
+	if err := fn(); err != nil {
+		telemetry.Log().Debug("proof phase failed", "circuit", c.circuit, "phase", phase, "err", err)
+		return err
+	}
+	elapsed := time.Since(start)
+	telemetry.Log().Debug("proof phase done", "circuit", c.circuit, "phase", phase, "took", elapsed)
+	telemetry.Stats().ProofPhase(c.circuit, string(phase), elapsed)
+	if c.progress != nil {
+		c.progress(ProofProgress{Phase: phase, Done: true, Elapsed: elapsed})
+	}
+	return nil
+}
+
+// prove runs the groth16 prover of gnark in a goroutine and returns ctx.Err() as soon as ctx is done. gnark can't
+// interrupt groth16.Prove: an abandoned proof runs to completion in the background and is dropped.
+func (t *GnarkGroth16) prove(ctx context.Context, fullWitness *witness.Witness, cfg *proveConfig) (groth16.Proof, error) {
+	var proof groth16.Proof
+	if err := cfg.run(ctx, PhaseProve, func() error {
+		type result struct {
+			proof groth16.Proof
+			err   error
+		}
+		res, finished := make(chan result, 1), make(chan struct{})
+		if cfg.proverDone != nil {
+			*cfg.proverDone = finished
+		}
+		go func() {
+			defer close(finished)
+			defer func() {
+				if r := recover(); r != nil {
+					res <- result{err: fmt.Errorf("groth16.Prove panicked: %v", r)}
+				}
+			}()
+			p, err := groth16.Prove(t.r1cs, t.pk, fullWitness, cfg.proverOptions...)
+			res <- result{proof: p, err: err}
+		}()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r := <-res:
+			if r.err != nil {
+				if errors.Is(r.err, witness.ErrInvalidWitness) {
+					return fmt.Errorf("%w: %v", ErrInvalidWitness, r.err)
+				}
+				return fmt.Errorf("%w: %v", ErrUnsatisfiedConstraints, r.err)
+			}
+			proof = r.proof
+			return nil
+		}
+	}); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
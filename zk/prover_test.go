@@ -0,0 +1,209 @@
+package zk_test
+
+import (
+	"context"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"gnark-bid/telemetry"
+	"gnark-bid/zk"
+	"gnark-bid/zk/circuits"
+	"gnark-bid/zk/zktest"
+	"math/big"
+	"runtime/debug"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGenerateProofProgress(t *testing.T) {
+	assert := test.NewAssert(t)
+	f := newBatchFixture(t, 0)
+	assignment := &zk_circuit.PrivateValueCircuit{
+		PrivateValue: 7,
+		Hash:         zk_circuit.HashMIMC(big.NewInt(7).Bytes()),
+	}
+
+	var phases []zk.ProofPhase
+	_, proof, err := f.g16.GenerateProof(context.Background(), assignment, zk.WithProgress(func(p zk.ProofProgress) {
+		if p.Done {
+			phases = append(phases, p.Phase)
+		}
+	}))
+	assert.NoError(err)
+	assert.Equal([]zk.ProofPhase{zk.PhaseWitness, zk.PhaseProve, zk.PhaseVerify}, phases)
+
+	// the proofs verify with gnark
+	publicWitness, err := frontend.NewWitness(assignment, ecc.BN254, frontend.PublicOnly())
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, f.vk, publicWitness))
+
+	ch := make(chan zk.ProofProgress, 16)
+	_, proof, err = f.g16.GenerateProof(context.Background(), assignment, zk.WithProgressChan(ch), zk.WithoutSelfVerify())
+	assert.NoError(err)
+	close(ch)
+	for p := range ch {
+		assert.NotEqual(zk.PhaseVerify, p.Phase, "self-verification should be skipped")
+	}
+	assert.NoError(groth16.Verify(proof, f.vk, publicWitness))
+
+	// unsatisfied constraints are reported by the solver
+	unsatisfied := &zk_circuit.PrivateValueCircuit{PrivateValue: 7, Hash: 8}
+	_, _, err = f.g16.GenerateProof(context.Background(), unsatisfied)
+	assert.ErrorIs(err, zk.ErrUnsatisfiedConstraints)
+
+	// unless the prover options of gnark say otherwise, the proof is then invalid
+	_, proof, err = f.g16.GenerateProof(context.Background(), unsatisfied, zk.WithProverOptions(backend.IgnoreSolverError()), zk.WithoutSelfVerify())
+	assert.NoError(err)
+	assert.Error(groth16.Verify(proof, f.vk, publicWitness))
+	_, _, err = f.g16.GenerateProof(context.Background(), unsatisfied, zk.WithProverOptions(backend.IgnoreSolverError()))
+	assert.ErrorIs(err, zk.ErrProofInvalid)
+}
+
+func TestGenerateProofCancel(t *testing.T) {
+	assert := test.NewAssert(t)
+	f := newBatchFixture(t, 0)
+	assignment := &zk_circuit.PrivateValueCircuit{
+		PrivateValue: 7,
+		Hash:         zk_circuit.HashMIMC(big.NewInt(7).Bytes()),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := f.g16.GenerateProof(ctx, assignment)
+	assert.ErrorIs(err, context.Canceled)
+
+	// cancel once the witness is built
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	var last zk.ProofPhase
+	_, _, err = f.g16.GenerateProof(ctx, assignment, zk.WithProgress(func(p zk.ProofProgress) {
+		last = p.Phase
+		if p.Phase == zk.PhaseWitness && p.Done {
+			cancel()
+		}
+	}))
+	assert.ErrorIs(err, context.Canceled)
+	assert.Equal(zk.PhaseWitness, last, "no phase should start after cancellation")
+
+	// a proof canceled while proving returns at once, gnark finishes it in the background
+	var circuit blockingCircuit
+	r1csCompiled, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit)
+	assert.NoError(err)
+	pk, vk, err := zktest.UnsafeSeededSetup(r1csCompiled, []byte(t.Name()))
+	assert.NoError(err)
+	vpKey, err := zk.CreateVPKey(pk, vk)
+	assert.NoError(err)
+	g16, err := zk.NewGnarkGroth16WithCS(vpKey, r1csCompiled, &circuit)
+	assert.NoError(err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	blockingHintRelease = make(chan struct{})
+	defer close(blockingHintRelease)
+	_, _, err = g16.GenerateProof(ctx, &blockingCircuit{X: 3, Y: 3}, zk.WithProverOptions(backend.WithHints(blockingHint)))
+	assert.ErrorIs(err, context.DeadlineExceeded, "the solver of groth16.Prove is still blocked")
+}
+
+// blockingHintRelease unblocks blockingHint
+var blockingHintRelease chan struct{}
+
+func blockingHint(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	<-blockingHintRelease
+	outputs[0].Set(inputs[0])
+	return nil
+}
+
+// blockingCircuit blocks groth16.Prove in the solver until blockingHintRelease is closed
+type blockingCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *blockingCircuit) Define(api frontend.API) error {
+	out, err := api.Compiler().NewHint(blockingHint, 1, c.X)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(out[0], c.X)
+	api.AssertIsEqual(c.X, c.Y)
+	return nil
+}
+
+type recordedMetrics struct {
+	telemetry.NopMetrics
+	mu       sync.Mutex
+	phases   []string
+	proofs   int
+	failures map[string]int
+}
+
+func (m *recordedMetrics) ProofPhase(circuit, phase string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.phases = append(m.phases, circuit+"/"+phase)
+}
+
+func (m *recordedMetrics) ProofGenerated(string, time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.proofs++
+}
+
+func (m *recordedMetrics) VerificationFailed(circuit, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures[circuit+"/"+reason]++
+}
+
+func TestProofMetrics(t *testing.T) {
+	assert := test.NewAssert(t)
+	f := newBatchFixture(t, 0)
+	m := &recordedMetrics{failures: map[string]int{}}
+	telemetry.SetMetrics(m)
+	defer telemetry.SetMetrics(nil)
+
+	assignment := &zk_circuit.PrivateValueCircuit{
+		PrivateValue: 7,
+		Hash:         zk_circuit.HashMIMC(big.NewInt(7).Bytes()),
+	}
+	_, proof, err := f.g16.GenerateProof(context.Background(), assignment, zk.WithoutSelfVerify())
+	assert.NoError(err)
+	assert.Equal([]string{"PrivateValueCircuit/witness", "PrivateValueCircuit/prove"}, m.phases)
+	assert.Equal(1, m.proofs)
+
+	ok, err := f.g16.VerifyProof(&zk_circuit.PrivateValueCircuit{PrivateValue: 0, Hash: 8}, proof)
+	assert.Error(err)
+	assert.False(ok)
+	assert.Equal(map[string]int{"PrivateValueCircuit/pairing": 1}, m.failures)
+}
+
+// The keys, proofs and constraint systems of gnark are read through reflection (groth16_bn254.go), with the layout of
+// the versions pinned here: upgrading gnark needs these accessors checked first.
+func TestGnarkLayout(t *testing.T) {
+	assert := test.NewAssert(t)
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		t.Skip("no build info")
+	}
+	versions := map[string]string{}
+	for _, dep := range info.Deps {
+		versions[dep.Path] = dep.Version
+	}
+	assert.Equal("v0.7.1", versions["github.com/consensys/gnark"])
+	assert.Equal("v0.7.0", versions["github.com/consensys/gnark-crypto"])
+
+	// every accessor reads its type
+	f := newBatchFixture(t, 1)
+	memory, err := f.g16.ProofMemory()
+	assert.NoError(err)
+	assert.NotZero(memory)
+	_, err = f.g16.Fingerprint()
+	assert.NoError(err)
+	invalid, err := zk.BatchVerify(f.vk, f.proofs, f.publicWitnesses)
+	assert.NoError(err)
+	assert.Empty(invalid)
+}
@@ -0,0 +1,234 @@
+package zk
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/schema"
+	"math/big"
+	"reflect"
+)
+
+// PublicInputSchema is the ordered list of the public inputs of a circuit, derived from its `gnark:",public"` tags.
+// The order is the order of the verifiers (gnark and Solidity), the names are the field paths of WitnessSchema.
+type PublicInputSchema struct {
+	Circuit string   `json:"circuit"`
+	Inputs  []string `json:"inputs"`
+}
+
+// NewPublicInputSchema returns the public input schema of a registered circuit
+func NewPublicInputSchema(name string) (*PublicInputSchema, error) {
+	circuit, err := NewCircuit(name)
+	if err != nil {
+		return nil, err
+	}
+	leaves, err := witnessLeaves(circuit)
+	if err != nil {
+		return nil, err
+	}
+	s := &PublicInputSchema{Circuit: name}
+	for _, leaf := range leaves {
+		if leaf.visibility == schema.Public {
+			s.Inputs = append(s.Inputs, leaf.path)
+		}
+	}
+	return s, nil
+}
+
+// Index returns the position of the input name, -1 if the circuit has no such public input
+func (s *PublicInputSchema) Index(name string) int {
+	for i, input := range s.Inputs {
+		if input == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// PublicInputs returns the public inputs of an ordered vector, e.g. the input of the Solidity verifier
+func (s *PublicInputSchema) PublicInputs(vector []*big.Int) (*PublicInputs, error) {
+	if len(vector) != len(s.Inputs) {
+		return nil, fmt.Errorf("%w: %s has %d public inputs, got %d", ErrInvalidPublicInput, s.Circuit, len(s.Inputs), len(vector))
+	}
+	if err := ValidatePublicInputs(vector); err != nil {
+		return nil, err
+	}
+	values := make([]*big.Int, len(vector))
+	for i, v := range vector {
+		values[i] = new(big.Int).Set(v)
+	}
+	return &PublicInputs{schema: s, values: values}, nil
+}
+
+// NamedPublicInputs returns the public inputs of a map keyed by their names, every input must be given
+func (s *PublicInputSchema) NamedPublicInputs(named map[string]*big.Int) (*PublicInputs, error) {
+	vector := make([]*big.Int, len(s.Inputs))
+	for i, name := range s.Inputs {
+		v, ok := named[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is missing", ErrInvalidPublicInput, name)
+		}
+		vector[i] = v
+	}
+	for name := range named {
+		if s.Index(name) < 0 {
+			return nil, fmt.Errorf("%w: %s is not a public input of %s", ErrInvalidPublicInput, name, s.Circuit)
+		}
+	}
+	return s.PublicInputs(vector)
+}
+
+// AssignmentPublicInputs returns the public inputs of an assignment of the circuit
+func (s *PublicInputSchema) AssignmentPublicInputs(assignment frontend.Circuit) (*PublicInputs, error) {
+	leaves, err := witnessLeaves(assignment)
+	if err != nil {
+		return nil, err
+	}
+	named := make(map[string]*big.Int, len(s.Inputs))
+	for _, leaf := range leaves {
+		if leaf.visibility != schema.Public {
+			continue
+		}
+		value, ok := assignmentValue(leaf.v.Interface())
+		if !ok || value == nil {
+			return nil, fmt.Errorf("%w: %s is not assigned an integer", ErrInvalidPublicInput, leaf.path)
+		}
+		named[leaf.path] = value
+	}
+	return s.NamedPublicInputs(named)
+}
+
+// PublicInputs are the values of the public inputs of a circuit, they can't be misordered: read them by name with
+// Map or Value, or in the order of the verifiers with Vector
+type PublicInputs struct {
+	schema *PublicInputSchema
+	values []*big.Int
+}
+
+// Schema returns the schema of the inputs
+func (p *PublicInputs) Schema() *PublicInputSchema {
+	return p.schema
+}
+
+// Vector returns the inputs in the order of the verifiers
+func (p *PublicInputs) Vector() []*big.Int {
+	vector := make([]*big.Int, len(p.values))
+	for i, v := range p.values {
+		vector[i] = new(big.Int).Set(v)
+	}
+	return vector
+}
+
+// Map returns the inputs keyed by name
+func (p *PublicInputs) Map() map[string]*big.Int {
+	named := make(map[string]*big.Int, len(p.values))
+	for i, v := range p.values {
+		named[p.schema.Inputs[i]] = new(big.Int).Set(v)
+	}
+	return named
+}
+
+// Value returns the input name
+func (p *PublicInputs) Value(name string) (*big.Int, bool) {
+	i := p.schema.Index(name)
+	if i < 0 {
+		return nil, false
+	}
+	return new(big.Int).Set(p.values[i]), true
+}
+
+// Assignment returns an assignment of the circuit to verify a proof: the public inputs are set and the secret
+// variables are zero
+func (p *PublicInputs) Assignment() (frontend.Circuit, error) {
+	assignment, err := NewCircuit(p.schema.Circuit)
+	if err != nil {
+		return nil, err
+	}
+	leaves, err := witnessLeaves(assignment)
+	if err != nil {
+		return nil, err
+	}
+	for _, leaf := range leaves {
+		value := big.NewInt(0)
+		if leaf.visibility == schema.Public {
+			value, _ = p.Value(leaf.path)
+		}
+		leaf.v.Set(reflect.ValueOf(value))
+	}
+	return assignment, nil
+}
+
+type publicInputsJSON struct {
+	Circuit string            `json:"circuit"`
+	Inputs  map[string]string `json:"inputs"`
+	Vector  []string          `json:"vector"`
+}
+
+// MarshalJSON encodes the inputs by name and in order, as decimal strings:
+// {"circuit": "BiddingCircuit", "inputs": {"BidValue": "100", ...}, "vector": ["...", ..., "100"]}
+func (p *PublicInputs) MarshalJSON() ([]byte, error) {
+	j := publicInputsJSON{
+		Circuit: p.schema.Circuit,
+		Inputs:  make(map[string]string, len(p.values)),
+		Vector:  make([]string, len(p.values)),
+	}
+	for i, v := range p.values {
+		j.Inputs[p.schema.Inputs[i]] = v.String()
+		j.Vector[i] = v.String()
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes the inputs of a registered circuit by name, or in order when only the vector is given. When
+// both are given they must agree.
+func (p *PublicInputs) UnmarshalJSON(data []byte) error {
+	var j publicInputsJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPublicInput, err)
+	}
+	s, err := NewPublicInputSchema(j.Circuit)
+	if err != nil {
+		return err
+	}
+
+	parse := func(name, value string) (*big.Int, error) {
+		v, err := parseWitnessValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %v", ErrInvalidPublicInput, name, err)
+		}
+		return v, nil
+	}
+	var vector []*big.Int
+	for i, value := range j.Vector {
+		v, err := parse(fmt.Sprintf("input %d", i), value)
+		if err != nil {
+			return err
+		}
+		vector = append(vector, v)
+	}
+
+	var inputs *PublicInputs
+	if j.Inputs != nil {
+		named := make(map[string]*big.Int, len(j.Inputs))
+		for name, value := range j.Inputs {
+			if named[name], err = parse(name, value); err != nil {
+				return err
+			}
+		}
+		if inputs, err = s.NamedPublicInputs(named); err != nil {
+			return err
+		}
+		for i, v := range vector {
+			if i >= len(inputs.values) || v.Cmp(inputs.values[i]) != 0 {
+				return fmt.Errorf("%w: the vector doesn't match the named inputs", ErrInvalidPublicInput)
+			}
+		}
+		if vector != nil && len(vector) != len(inputs.values) {
+			return fmt.Errorf("%w: the vector doesn't match the named inputs", ErrInvalidPublicInput)
+		}
+	} else if inputs, err = s.PublicInputs(vector); err != nil {
+		return err
+	}
+	*p = *inputs
+	return nil
+}
@@ -0,0 +1,91 @@
+package zk_test
+
+import (
+	"encoding/json"
+	"github.com/consensys/gnark/test"
+	"gnark-bid/zk"
+	"math/big"
+	"testing"
+)
+
+func TestPublicInputSchema(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	s, err := zk.NewPublicInputSchema("BiddingCircuit")
+	assert.NoError(err)
+	assert.Equal([]string{"UserMerkleRoot", "Identity.Nullifier", "Identity.Commitment", "Identity.Trapdoor", "BidValue"}, s.Inputs)
+	assert.Equal(4, s.Index("BidValue"))
+	assert.Equal(-1, s.Index("UserData.UserID"))
+
+	s, err = zk.NewPublicInputSchema("PrivateValueCircuit")
+	assert.NoError(err)
+	assert.Equal([]string{"Hash"}, s.Inputs)
+
+	raw, err := json.Marshal(s)
+	assert.NoError(err)
+	assert.Equal(`{"circuit":"PrivateValueCircuit","inputs":["Hash"]}`, string(raw))
+}
+
+func TestPublicInputs(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	bidding, err := zk.NewBidding(nil)
+	assert.NoError(err)
+	assert.NoError(bidding.InitSession(1111, "username_2", big.NewInt(1111222233334444)))
+	proof, inputs, err := bidding.GetProofInputs(big.NewInt(100))
+	assert.NoError(err)
+
+	bid, ok := inputs.Value("BidValue")
+	assert.True(ok)
+	assert.Equal(big.NewInt(100), bid)
+	assert.Equal(bidding.GetIdentity().Trapdoor, inputs.Map()["Identity.Trapdoor"])
+	assert.Equal(bidding.GetIdentity().Nullifier, inputs.Vector()[1])
+
+	ok, err = bidding.VerifyProofInputs(proof, inputs)
+	assert.NoError(err)
+	assert.True(ok)
+
+	// JSON, by name and by position
+	raw, err := json.Marshal(inputs)
+	assert.NoError(err)
+	var decoded zk.PublicInputs
+	assert.NoError(json.Unmarshal(raw, &decoded))
+	assert.Equal(inputs.Vector(), decoded.Vector())
+
+	var j struct {
+		Circuit string            `json:"circuit"`
+		Inputs  map[string]string `json:"inputs"`
+		Vector  []string          `json:"vector"`
+	}
+	assert.NoError(json.Unmarshal(raw, &j))
+	named, _ := json.Marshal(map[string]interface{}{"circuit": j.Circuit, "inputs": j.Inputs})
+	assert.NoError(json.Unmarshal(named, &decoded))
+	assert.Equal(inputs.Vector(), decoded.Vector())
+	vector, _ := json.Marshal(map[string]interface{}{"circuit": j.Circuit, "vector": j.Vector})
+	assert.NoError(json.Unmarshal(vector, &decoded))
+	assert.Equal(inputs.Vector(), decoded.Vector())
+
+	// a misordered vector doesn't match the names
+	j.Vector[1], j.Vector[2] = j.Vector[2], j.Vector[1]
+	misordered, _ := json.Marshal(j)
+	assert.ErrorIs(json.Unmarshal(misordered, &decoded), zk.ErrInvalidPublicInput)
+
+	s := bidding.PublicInputSchema()
+	m := inputs.Map()
+	delete(m, "BidValue")
+	_, err = s.NamedPublicInputs(m)
+	assert.ErrorIs(err, zk.ErrInvalidPublicInput)
+	m["BidValue"], m["UserData.UserID"] = big.NewInt(100), big.NewInt(1)
+	_, err = s.NamedPublicInputs(m)
+	assert.ErrorIs(err, zk.ErrInvalidPublicInput)
+	_, err = s.PublicInputs(inputs.Vector()[:4])
+	assert.ErrorIs(err, zk.ErrInvalidPublicInput)
+
+	// the inputs of another circuit
+	other, err := zk.NewPublicInputSchema("PrivateValueCircuit")
+	assert.NoError(err)
+	otherInputs, err := other.PublicInputs([]*big.Int{big.NewInt(1)})
+	assert.NoError(err)
+	_, err = bidding.VerifyProofInputs(proof, otherInputs)
+	assert.ErrorIs(err, zk.ErrInvalidPublicInput)
+}
@@ -0,0 +1,79 @@
+package zk
+
+import (
+	"fmt"
+	"github.com/consensys/gnark/frontend"
+	zkCircuit "gnark-bid/zk/circuits"
+	"sort"
+	"sync"
+)
+
+var registry = struct {
+	sync.RWMutex
+	circuits map[string]func() frontend.Circuit
+}{circuits: make(map[string]func() frontend.Circuit)}
+
+func init() {
+	mustRegisterCircuit("BiddingCircuit", func() frontend.Circuit {
+		var c zkCircuit.BiddingCircuit
+		c.UserMerklePath = make([]frontend.Variable, MerkleTreeDepth+1)
+		c.UserMerkleHelper = make([]frontend.Variable, MerkleTreeDepth)
+		return &c
+	})
+	mustRegisterCircuit("CommittedBidCircuit", func() frontend.Circuit {
+		return &zkCircuit.CommittedBidCircuit{}
+	})
+	mustRegisterCircuit("MerkleCircuit", func() frontend.Circuit {
+		var c zkCircuit.MerkleCircuit
+		c.Path = make([]frontend.Variable, MerkleTreeDepth+1)
+		c.Helper = make([]frontend.Variable, MerkleTreeDepth)
+		return &c
+	})
+	mustRegisterCircuit("PrivateValueCircuit", func() frontend.Circuit {
+		return &zkCircuit.PrivateValueCircuit{}
+	})
+}
+
+// RegisterCircuit registers a constructor returning an empty circuit (slices sized) ready to be compiled, the name
+// must not be registered yet: the bundle keys of a name stay the keys of its circuit
+func RegisterCircuit(name string, newCircuit func() frontend.Circuit) error {
+	if name == "" || newCircuit == nil {
+		return fmt.Errorf("circuit %q needs a name and a constructor", name)
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.circuits[name]; ok {
+		return fmt.Errorf("circuit %q is already registered", name)
+	}
+	registry.circuits[name] = newCircuit
+	return nil
+}
+
+func mustRegisterCircuit(name string, newCircuit func() frontend.Circuit) {
+	if err := RegisterCircuit(name, newCircuit); err != nil {
+		panic(err)
+	}
+}
+
+// NewCircuit returns an empty registered circuit
+func NewCircuit(name string) (frontend.Circuit, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+	newCircuit, ok := registry.circuits[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown circuit %s", name)
+	}
+	return newCircuit(), nil
+}
+
+// CircuitNames returns the sorted names of the registered circuits
+func CircuitNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, 0, len(registry.circuits))
+	for name := range registry.circuits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
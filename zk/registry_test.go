@@ -0,0 +1,30 @@
+package zk_test
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"gnark-bid/zk"
+	"gnark-bid/zk/circuits"
+	"testing"
+)
+
+func TestRegisterCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assert.Equal([]string{"BiddingCircuit", "CommittedBidCircuit", "MerkleCircuit", "PrivateValueCircuit"}, zk.CircuitNames())
+	_, err := zk.NewCircuit("UnknownCircuit")
+	assert.Error(err)
+
+	// the registered circuits can't be replaced
+	newCircuit := func() frontend.Circuit {
+		return &zk_circuit.PrivateValueCircuit{}
+	}
+	assert.Error(zk.RegisterCircuit("BiddingCircuit", newCircuit))
+	assert.Error(zk.RegisterCircuit("", newCircuit))
+	assert.Error(zk.RegisterCircuit("UnknownCircuit", nil))
+	_, err = zk.NewCircuit("UnknownCircuit")
+	assert.Error(err)
+	circuit, err := zk.NewCircuit("BiddingCircuit")
+	assert.NoError(err)
+	assert.IsType(&zk_circuit.BiddingCircuit{}, circuit, "BiddingCircuit was replaced")
+}
@@ -0,0 +1,268 @@
+package zk
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// CircuitStats is the size of a compiled circuit, with the constraints of the gadgets scoped by circuits.Gadget
+type CircuitStats struct {
+	Circuit             string        `json:"circuit"`
+	NbConstraints       int           `json:"nbConstraints"`
+	NbPublicVariables   int           `json:"nbPublicVariables"`
+	NbSecretVariables   int           `json:"nbSecretVariables"`
+	NbInternalVariables int           `json:"nbInternalVariables"`
+	Gadgets             []GadgetStats `json:"gadgets"`
+}
+
+// GadgetStats are the constraints and internal variables created by the calls of a gadget, the constraints of a
+// nested gadget are only counted in the nested gadget
+type GadgetStats struct {
+	Name                string `json:"name"`
+	Calls               int    `json:"calls"`
+	NbConstraints       int    `json:"nbConstraints"`
+	NbInternalVariables int    `json:"nbInternalVariables"`
+}
+
+// Gadget returns the stats of the gadget name, zero if the circuit doesn't call it
+func (s CircuitStats) Gadget(name string) GadgetStats {
+	for _, g := range s.Gadgets {
+		if g.Name == name {
+			return g
+		}
+	}
+	return GadgetStats{Name: name}
+}
+
+// Other is the number of constraints created outside any gadget
+func (s CircuitStats) Other() int {
+	other := s.NbConstraints
+	for _, g := range s.Gadgets {
+		other -= g.NbConstraints
+	}
+	return other
+}
+
+// NewCircuitStats compiles a registered circuit and measures it
+func NewCircuitStats(name string) (CircuitStats, error) {
+	circuit, err := NewCircuit(name)
+	if err != nil {
+		return CircuitStats{}, err
+	}
+	return CompileStats(name, circuit)
+}
+
+// CompileStats compiles circuit and measures it
+func CompileStats(name string, circuit frontend.Circuit) (CircuitStats, error) {
+	var recorder *gadgetRecorder
+	r1csCompiled, err := frontend.Compile(ecc.BN254, func(curve ecc.ID, config frontend.CompileConfig) (frontend.Builder, error) {
+		builder, err := r1cs.NewBuilder(curve, config)
+		if err != nil {
+			return nil, err
+		}
+		recorder = &gadgetRecorder{Builder: builder, gadgets: make(map[string]*GadgetStats)}
+		return recorder, nil
+	}, circuit)
+	if err != nil {
+		return CircuitStats{}, err
+	}
+
+	internal, secret, public := r1csCompiled.GetNbVariables()
+	stats := CircuitStats{
+		Circuit:             name,
+		NbConstraints:       r1csCompiled.GetNbConstraints(),
+		NbPublicVariables:   public,
+		NbSecretVariables:   secret,
+		NbInternalVariables: internal,
+		Gadgets:             make([]GadgetStats, 0, len(recorder.gadgets)),
+	}
+	for _, g := range recorder.gadgets {
+		stats.Gadgets = append(stats.Gadgets, *g)
+	}
+	sort.Slice(stats.Gadgets, func(i, j int) bool { return stats.Gadgets[i].Name < stats.Gadgets[j].Name })
+	return stats, nil
+}
+
+// CircuitReport measures the given registered circuits, all of them when names is empty
+func CircuitReport(names ...string) ([]CircuitStats, error) {
+	if len(names) == 0 {
+		names = CircuitNames()
+	}
+	report := make([]CircuitStats, 0, len(names))
+	for _, name := range names {
+		stats, err := NewCircuitStats(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		report = append(report, stats)
+	}
+	return report, nil
+}
+
+// WriteReport writes the report as a text table
+func WriteReport(w io.Writer, report []CircuitStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "circuit\tconstraints\tpublic\tsecret\tinternal\t")
+	for _, s := range report {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t\n", s.Circuit, s.NbConstraints, s.NbPublicVariables, s.NbSecretVariables, s.NbInternalVariables)
+	}
+	fmt.Fprintln(tw, "\t\t\t\t\t")
+	fmt.Fprintln(tw, "circuit\tgadget\tcalls\tconstraints\tshare\t")
+	for _, s := range report {
+		for _, g := range s.Gadgets {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t\n", s.Circuit, g.Name, g.Calls, g.NbConstraints, share(g.NbConstraints, s.NbConstraints))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t\t%d\t%s\t\n", s.Circuit, "other", s.Other(), share(s.Other(), s.NbConstraints))
+	}
+	return tw.Flush()
+}
+
+func share(n, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(n)/float64(total))
+}
+
+// Budget is the maximum number of constraints of circuits, and optionally of their gadgets, checked in CI
+type Budget map[string]CircuitBudget
+
+// CircuitBudget is the budget of a circuit, gadgets are keyed by name
+type CircuitBudget struct {
+	NbConstraints int            `json:"nbConstraints"`
+	Gadgets       map[string]int `json:"gadgets,omitempty"`
+}
+
+// NewBudget returns the counts of the report as a budget
+func NewBudget(report []CircuitStats) Budget {
+	b := make(Budget, len(report))
+	for _, s := range report {
+		cb := CircuitBudget{NbConstraints: s.NbConstraints, Gadgets: make(map[string]int, len(s.Gadgets))}
+		for _, g := range s.Gadgets {
+			cb.Gadgets[g.Name] = g.NbConstraints
+		}
+		b[s.Circuit] = cb
+	}
+	return b
+}
+
+// ReadBudget reads a budget file
+func ReadBudget(fileName string) (Budget, error) {
+	raw, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var b Budget
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return nil, fmt.Errorf("budget %s: %w", fileName, err)
+	}
+	return b, nil
+}
+
+// WriteFile writes the budget as indented JSON
+func (b Budget) WriteFile(fileName string) error {
+	raw, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, append(raw, '\n'), 0o644)
+}
+
+// Circuits returns the sorted names of the circuits of the budget
+func (b Budget) Circuits() []string {
+	names := make([]string, 0, len(b))
+	for name := range b {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check returns an error listing every circuit and gadget of the report over budget, the circuits without a budget
+// are not checked
+func (b Budget) Check(report []CircuitStats) error {
+	var over []string
+	for _, s := range report {
+		cb, ok := b[s.Circuit]
+		if !ok {
+			continue
+		}
+		if s.NbConstraints > cb.NbConstraints {
+			over = append(over, fmt.Sprintf("%s: %d constraints, budget %d", s.Circuit, s.NbConstraints, cb.NbConstraints))
+		}
+		names := make([]string, 0, len(cb.Gadgets))
+		for name := range cb.Gadgets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if n := s.Gadget(name).NbConstraints; n > cb.Gadgets[name] {
+				over = append(over, fmt.Sprintf("%s/%s: %d constraints, budget %d", s.Circuit, name, n, cb.Gadgets[name]))
+			}
+		}
+	}
+	if len(over) > 0 {
+		return fmt.Errorf("constraint budget exceeded:\n%s", strings.Join(over, "\n"))
+	}
+	return nil
+}
+
+// gadgetRecorder implements circuits.GadgetRecorder with the tags of the builder, they don't add anything to the
+// constraint system
+type gadgetRecorder struct {
+	frontend.Builder
+	stack   []gadgetFrame
+	gadgets map[string]*GadgetStats
+}
+
+type gadgetFrame struct {
+	name                               string
+	tag                                frontend.Tag
+	nestedConstraints, nestedVariables int
+	// a gadget called by itself (e.g. LessEqThan calling GreaterThan) is part of the outer call
+	merged bool
+}
+
+func (r *gadgetRecorder) BeginGadget(name string) {
+	merged := len(r.stack) > 0 && r.stack[len(r.stack)-1].name == name
+	r.stack = append(r.stack, gadgetFrame{name: name, tag: r.Compiler().Tag(name), merged: merged})
+}
+
+func (r *gadgetRecorder) EndGadget(name string) {
+	f := r.stack[len(r.stack)-1]
+	r.stack = r.stack[:len(r.stack)-1]
+	if f.name != name {
+		panic(fmt.Sprintf("gadget %s ended inside %s", name, f.name))
+	}
+	if f.merged {
+		// the outer call counts the constraints, except the ones of the gadgets nested in this call
+		parent := &r.stack[len(r.stack)-1]
+		parent.nestedConstraints += f.nestedConstraints
+		parent.nestedVariables += f.nestedVariables
+		return
+	}
+	end := r.Compiler().Tag(name)
+	constraints, variables := end.CID-f.tag.CID, end.VID-f.tag.VID
+
+	g, ok := r.gadgets[name]
+	if !ok {
+		g = &GadgetStats{Name: name}
+		r.gadgets[name] = g
+	}
+	g.Calls++
+	g.NbConstraints += constraints - f.nestedConstraints
+	g.NbInternalVariables += variables - f.nestedVariables
+	if len(r.stack) > 0 {
+		parent := &r.stack[len(r.stack)-1]
+		parent.nestedConstraints += constraints
+		parent.nestedVariables += variables
+	}
+}
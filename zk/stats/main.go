@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"gnark-bid/zk"
+	"log"
+	"os"
+	"strings"
+)
+
+const usage = `usage: stats <command> [flags]
+
+Constraint counts of the registered circuits, with a breakdown per gadget.
+
+commands:
+  report  print the counts of the circuits
+  check   fail when the counts exceed the budget file
+  budget  write the current counts to the budget file
+
+circuits: %s
+`
+
+const defaultBudget = "zk/testdata/constraint_budget.json"
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintf(os.Stderr, usage, strings.Join(zk.CircuitNames(), ", "))
+		os.Exit(2)
+	}
+	cmd, args := os.Args[1], os.Args[2:]
+	switch cmd {
+	case "report":
+		report(args)
+	case "check":
+		check(args)
+	case "budget":
+		budget(args)
+	default:
+		fmt.Fprintf(os.Stderr, usage, strings.Join(zk.CircuitNames(), ", "))
+		os.Exit(2)
+	}
+}
+
+func report(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	circuits := fs.String("circuits", "", "comma separated circuits, all of them by default")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	_ = fs.Parse(args)
+
+	r := circuitReport(*circuits)
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(r); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := zk.WriteReport(os.Stdout, r); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func check(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	budgetFile := fs.String("budget", defaultBudget, "budget file")
+	_ = fs.Parse(args)
+
+	b, err := zk.ReadBudget(*budgetFile)
+	if err != nil {
+		log.Fatal("read budget error:", err)
+	}
+	r := circuitReport(strings.Join(b.Circuits(), ","))
+	if err := zk.WriteReport(os.Stdout, r); err != nil {
+		log.Fatal(err)
+	}
+	if err := b.Check(r); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("budget ok")
+}
+
+func budget(args []string) {
+	fs := flag.NewFlagSet("budget", flag.ExitOnError)
+	circuits := fs.String("circuits", "", "comma separated circuits, all of them by default")
+	out := fs.String("out", defaultBudget, "budget file to write")
+	_ = fs.Parse(args)
+
+	if err := zk.NewBudget(circuitReport(*circuits)).WriteFile(*out); err != nil {
+		log.Fatal("write budget error:", err)
+	}
+	fmt.Println("budget written:", *out)
+}
+
+func circuitReport(circuits string) []zk.CircuitStats {
+	var names []string
+	if circuits != "" {
+		names = strings.Split(circuits, ",")
+	}
+	r, err := zk.CircuitReport(names...)
+	if err != nil {
+		log.Fatal("compile error:", err)
+	}
+	return r
+}
@@ -0,0 +1,64 @@
+package zk_test
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"gnark-bid/circuits"
+	"gnark-bid/zk"
+	"gnark-bid/zk/zktest"
+	"testing"
+)
+
+type gadgetsCircuit struct {
+	A, B frontend.Variable
+}
+
+func (c *gadgetsCircuit) Define(api frontend.API) error {
+	// LessEqThan calls GreaterThan and IsEqual: a single comparator call
+	api.AssertIsEqual(circuits.LessEqThan(api, c.A, c.B), 1)
+	circuits.Gadget(api, circuits.GadgetMerkle, func() {
+		api.AssertIsEqual(circuits.Poseidon(api, []frontend.Variable{c.A, c.B}), circuits.Poseidon(api, []frontend.Variable{c.B}))
+		api.AssertIsEqual(api.Mul(c.A, c.B), 6)
+	})
+	return nil
+}
+
+func TestCircuitStats(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	stats, err := zk.CompileStats("gadgets", &gadgetsCircuit{})
+	assert.NoError(err)
+	assert.Equal(1, stats.Gadget(circuits.GadgetComparator).Calls)
+	assert.Equal(2, stats.Gadget(circuits.GadgetPoseidon).Calls)
+	assert.Equal(0, stats.Gadget(circuits.GadgetMiMC).Calls)
+
+	// the merkle gadget only counts its own constraints, not the ones of the poseidon calls
+	merkle := stats.Gadget(circuits.GadgetMerkle)
+	assert.Equal(1, merkle.Calls)
+	assert.Equal(3, merkle.NbConstraints)
+	sum := stats.Other()
+	for _, g := range stats.Gadgets {
+		sum += g.NbConstraints
+	}
+	assert.Equal(stats.NbConstraints, sum)
+
+	bidding, err := zk.NewCircuitStats("BiddingCircuit")
+	assert.NoError(err)
+	assert.Equal(2, bidding.Gadget(circuits.GadgetPoseidon).Calls)
+	assert.Equal(5+1, bidding.NbPublicVariables)
+}
+
+func TestConstraintBudget(t *testing.T) {
+	assert := test.NewAssert(t)
+	zktest.CheckBudget(t, "testdata/constraint_budget.json")
+
+	report, err := zk.CircuitReport("PrivateValueCircuit")
+	assert.NoError(err)
+	budget := zk.NewBudget(report)
+	assert.NoError(budget.Check(report))
+
+	tight := zk.Budget{"PrivateValueCircuit": {NbConstraints: report[0].NbConstraints - 1, Gadgets: map[string]int{circuits.GadgetPoseidon: 0}}}
+	assert.Error(tight.Check(report))
+	tight["PrivateValueCircuit"] = zk.CircuitBudget{NbConstraints: report[0].NbConstraints, Gadgets: map[string]int{circuits.GadgetMiMC: 100}}
+	assert.Error(tight.Check(report))
+}
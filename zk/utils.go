@@ -30,16 +30,18 @@ func ParserProof(proofBytes []byte) *Proof {
 	return proof
 }
 
+// ProofToBytes is the inverse of ParserProof, the coordinates must be valid (see ValidateProof)
 func ProofToBytes(proof *Proof) []byte {
-	proofBytes := make([]byte, 0, fpSize*8)
-	proofBytes = append(proofBytes, proof.A[0].Bytes()...)
-	proofBytes = append(proofBytes, proof.A[1].Bytes()...)
-	proofBytes = append(proofBytes, proof.B[0][0].Bytes()...)
-	proofBytes = append(proofBytes, proof.B[0][1].Bytes()...)
-	proofBytes = append(proofBytes, proof.B[1][0].Bytes()...)
-	proofBytes = append(proofBytes, proof.B[1][1].Bytes()...)
-	proofBytes = append(proofBytes, proof.C[0].Bytes()...)
-	proofBytes = append(proofBytes, proof.C[1].Bytes()...)
+	proofBytes := make([]byte, fpSize*8)
+	// fixed size words: Bytes() drops the leading zeros of small coordinates
+	proof.A[0].FillBytes(proofBytes[fpSize*0 : fpSize*1])
+	proof.A[1].FillBytes(proofBytes[fpSize*1 : fpSize*2])
+	proof.B[0][0].FillBytes(proofBytes[fpSize*2 : fpSize*3])
+	proof.B[0][1].FillBytes(proofBytes[fpSize*3 : fpSize*4])
+	proof.B[1][0].FillBytes(proofBytes[fpSize*4 : fpSize*5])
+	proof.B[1][1].FillBytes(proofBytes[fpSize*5 : fpSize*6])
+	proof.C[0].FillBytes(proofBytes[fpSize*6 : fpSize*7])
+	proof.C[1].FillBytes(proofBytes[fpSize*7 : fpSize*8])
 
 	return proofBytes
 }
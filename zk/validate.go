@@ -0,0 +1,118 @@
+package zk
+
+import (
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/schema"
+	"math/big"
+	"reflect"
+)
+
+// The Solidity verifier rejects public inputs >= SNARK_SCALAR_FIELD (r) and proof coordinates >= PRIME_Q (q), while
+// gnark silently reduces them. Without these checks an aliased input (x + r) verifies off-chain and fails on-chain.
+
+// ValidatePublicInputs checks that the inputs are canonical scalar field elements: not nil and 0 <= x < r
+func ValidatePublicInputs(inputs []*big.Int) error {
+	r := fr.Modulus()
+	for i, input := range inputs {
+		if err := checkCanonical(input, r); err != nil {
+			return fmt.Errorf("%w: input %d %v", ErrInvalidPublicInput, i, err)
+		}
+	}
+	return nil
+}
+
+// ValidateProof checks that the coordinates of the proof are canonical base field elements: not nil and 0 <= x < q
+func ValidateProof(proof *Proof) error {
+	if proof == nil {
+		return fmt.Errorf("%w: missing proof", ErrProofInvalid)
+	}
+	q := fp.Modulus()
+	for _, c := range []struct {
+		name string
+		x    *big.Int
+	}{
+		{"a[0]", proof.A[0]}, {"a[1]", proof.A[1]},
+		{"b[0][0]", proof.B[0][0]}, {"b[0][1]", proof.B[0][1]}, {"b[1][0]", proof.B[1][0]}, {"b[1][1]", proof.B[1][1]},
+		{"c[0]", proof.C[0]}, {"c[1]", proof.C[1]},
+	} {
+		if err := checkCanonical(c.x, q); err != nil {
+			return fmt.Errorf("%w: %s %v", ErrProofInvalid, c.name, err)
+		}
+	}
+	return nil
+}
+
+// validateAssignment checks the public inputs of an assignment before gnark reduces them, see ValidatePublicInputs
+func validateAssignment(assignment frontend.Circuit) error {
+	r := fr.Modulus()
+	_, err := schema.Parse(assignment, tVariable, func(visibility schema.Visibility, name string, v reflect.Value) error {
+		if visibility != schema.Public {
+			return nil
+		}
+		value, ok := assignmentValue(v.Interface())
+		if !ok {
+			// gnark panics on the strings that aren't integers
+			if _, isString := v.Interface().(string); isString {
+				return fmt.Errorf("%w: %s is not an integer", ErrInvalidPublicInput, name)
+			}
+			// field elements and other types gnark converts are canonical
+			return nil
+		}
+		if err := checkCanonical(value, r); err != nil {
+			return fmt.Errorf("%w: %s %v", ErrInvalidPublicInput, name, err)
+		}
+		return nil
+	})
+	return err
+}
+
+// assignmentValue returns the integer of an assigned variable, ok is false for the types that can't be out of range
+func assignmentValue(v interface{}) (*big.Int, bool) {
+	switch v := v.(type) {
+	case nil:
+		return nil, true
+	case *big.Int:
+		return v, true
+	case big.Int:
+		return &v, true
+	case *fr.Element:
+		if v == nil {
+			return nil, true
+		}
+		return v.ToBigIntRegular(new(big.Int)), true
+	case fr.Element:
+		return v.ToBigIntRegular(new(big.Int)), true
+	case int:
+		return big.NewInt(int64(v)), true
+	case int8:
+		return big.NewInt(int64(v)), true
+	case int16:
+		return big.NewInt(int64(v)), true
+	case int32:
+		return big.NewInt(int64(v)), true
+	case int64:
+		return big.NewInt(v), true
+	case []byte:
+		return new(big.Int).SetBytes(v), true
+	case string:
+		if i, ok := new(big.Int).SetString(v, 0); ok {
+			return i, true
+		}
+	}
+	return nil, false
+}
+
+func checkCanonical(x, modulus *big.Int) error {
+	switch {
+	case x == nil:
+		return fmt.Errorf("is missing")
+	case x.Sign() < 0:
+		return fmt.Errorf("is negative")
+	case x.Cmp(modulus) >= 0:
+		return fmt.Errorf("is not below the field modulus")
+	}
+	return nil
+}
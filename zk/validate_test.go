@@ -0,0 +1,86 @@
+package zk_test
+
+import (
+	"context"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/test"
+	"gnark-bid/zk"
+	"gnark-bid/zk/circuits"
+	"math/big"
+	"testing"
+)
+
+func TestValidatePublicInputs(t *testing.T) {
+	assert := test.NewAssert(t)
+	r := fr.Modulus()
+
+	assert.NoError(zk.ValidatePublicInputs([]*big.Int{big.NewInt(0), new(big.Int).Sub(r, big.NewInt(1))}))
+	for _, input := range []*big.Int{nil, big.NewInt(-1), r, new(big.Int).Add(r, big.NewInt(5))} {
+		assert.ErrorIs(zk.ValidatePublicInputs([]*big.Int{big.NewInt(1), input}), zk.ErrInvalidPublicInput, "input %v", input)
+	}
+}
+
+func TestValidateProof(t *testing.T) {
+	assert := test.NewAssert(t)
+	q := fp.Modulus()
+	valid := func() *zk.Proof {
+		one := func() *big.Int { return big.NewInt(1) }
+		return &zk.Proof{
+			A: [2]*big.Int{one(), one()},
+			B: [2][2]*big.Int{{one(), one()}, {one(), one()}},
+			C: [2]*big.Int{one(), new(big.Int).Sub(q, big.NewInt(1))},
+		}
+	}
+
+	assert.NoError(zk.ValidateProof(valid()))
+	assert.ErrorIs(zk.ValidateProof(nil), zk.ErrProofInvalid)
+	for _, coordinate := range []*big.Int{nil, big.NewInt(-1), q} {
+		p := valid()
+		p.B[1][0] = coordinate
+		assert.ErrorIs(zk.ValidateProof(p), zk.ErrProofInvalid, "coordinate %v", coordinate)
+	}
+
+	// small coordinates keep their position in the raw encoding
+	p := valid()
+	assert.Equal(p, zk.ParserProof(zk.ProofToBytes(p)))
+}
+
+func TestAliasedPublicInputs(t *testing.T) {
+	assert := test.NewAssert(t)
+	r := fr.Modulus()
+
+	// BiddingCircuit: the bid + r is the same field element, the contract rejects it
+	bidding, err := zk.NewBidding(nil)
+	assert.NoError(err)
+	assert.NoError(bidding.InitSession(1111, "username_2", big.NewInt(1111222233334444)))
+	proof, inputs, err := bidding.GetProof(big.NewInt(100))
+	assert.NoError(err)
+	ok, err := bidding.VerifyProof(proof, inputs)
+	assert.NoError(err)
+	assert.True(ok)
+
+	aliased := inputs
+	aliased[4] = new(big.Int).Add(inputs[4], r)
+	ok, err = bidding.VerifyProof(proof, aliased)
+	assert.False(ok)
+	assert.ErrorIs(err, zk.ErrInvalidPublicInput)
+
+	aliasedProof := *proof
+	aliasedProof.A[0] = new(big.Int).Add(proof.A[0], fp.Modulus())
+	_, err = bidding.VerifyProof(&aliasedProof, inputs)
+	assert.ErrorIs(err, zk.ErrProofInvalid)
+
+	// the assignments of any circuit
+	f := newBatchFixture(t, 1)
+	value := f.assignments[0].(*zk_circuit.PrivateValueCircuit)
+	hash := value.Hash.(*big.Int)
+	for _, h := range []interface{}{new(big.Int).Add(hash, r), new(big.Int).Sub(hash, r), r.Bytes(), -1} {
+		assignment := &zk_circuit.PrivateValueCircuit{PrivateValue: value.PrivateValue, Hash: h}
+		ok, err = f.g16.VerifyProof(assignment, f.proofs[0])
+		assert.False(ok)
+		assert.ErrorIs(err, zk.ErrInvalidPublicInput, "hash %v", h)
+		_, _, err = f.g16.GenerateProof(context.Background(), assignment)
+		assert.ErrorIs(err, zk.ErrInvalidPublicInput, "hash %v", h)
+	}
+}
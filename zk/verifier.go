@@ -2,60 +2,118 @@ package zk
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/ethereum/go-ethereum/common"
-	"log"
+	"gnark-bid/telemetry"
+	"sync"
 	"time"
 )
 
+// GnarkGroth16 is safe for concurrent use once constructed: the keys and the constraint system are only read while
+// proving and verifying, so one instance can be shared by many goroutines (see ProverPool)
 type GnarkGroth16 struct {
 	vk   groth16.VerifyingKey
 	pk   groth16.ProvingKey
 	r1cs frontend.CompiledConstraintSystem
+
+	name    string
+	version int
+	circuit frontend.Circuit // the circuit the keys were loaded for, e.g. for the Hash of a BiddingCircuit
+
+	mu          sync.Mutex // guards fingerprint
+	fingerprint *Fingerprint
 }
 
 func NewGnarkGroth16(key *VPKey, circuit frontend.Circuit) (*GnarkGroth16, error) {
-	g16 := &GnarkGroth16{}
-
-	if _r1cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, circuit); err != nil {
-		log.Println(err, "compiling R1CS failed")
+	_r1cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, circuit)
+	if err != nil {
+		telemetry.Log().Error("compiling R1CS failed", "circuit", CircuitName(circuit), "err", err)
 		return nil, err
-	} else {
-		g16.r1cs = _r1cs
+	}
+
+	return NewGnarkGroth16WithCS(key, _r1cs, circuit)
+}
+
+// NewGnarkGroth16WithCS uses an already compiled constraint system (e.g. loaded from a bundle) instead of compiling circuit,
+// circuit is only used to check the constraint system matches the keys and the expected inputs
+func NewGnarkGroth16WithCS(key *VPKey, r1cs frontend.CompiledConstraintSystem, circuit frontend.Circuit) (*GnarkGroth16, error) {
+	g16 := &GnarkGroth16{
+		r1cs:    r1cs,
+		name:    CircuitName(circuit),
+		version: CircuitVersion(circuit),
+		circuit: circuit,
 	}
 
 	if err := g16.setup(key); err != nil { // take a long time
 		return nil, err
 	}
+	if err := CheckConstraintSystem(g16.r1cs, g16.pk, g16.vk, circuit); err != nil {
+		return nil, err
+	}
+	if err := checkKeyPair(g16.pk, g16.vk); err != nil {
+		return nil, err
+	}
+	telemetry.Log().Debug("prover loaded", "circuit", g16.name, "version", g16.version, "constraints", r1cs.GetNbConstraints())
+	telemetry.Stats().ConstraintCount(g16.name, r1cs.GetNbConstraints())
 	return g16, nil
 }
 
+// Fingerprint returns the fingerprint of the verifying key and the constraint system
+func (t *GnarkGroth16) Fingerprint() (Fingerprint, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.fingerprintLocked()
+}
+
+func (t *GnarkGroth16) fingerprintLocked() (Fingerprint, error) {
+	if t.fingerprint == nil {
+		fp, err := NewFingerprint(t.name, t.version, t.r1cs, t.vk)
+		if err != nil {
+			return Fingerprint{}, err
+		}
+		t.fingerprint = &fp
+	}
+	return *t.fingerprint, nil
+}
+
+// CheckVerifierFingerprint returns ErrFingerprintMismatch when digest, the fingerprint digest of a verifier (e.g. the
+// FINGERPRINT of the deployed contract), doesn't match the keys
+func (t *GnarkGroth16) CheckVerifierFingerprint(digest common.Hash) error {
+	fp, err := t.Fingerprint()
+	if err != nil {
+		return err
+	}
+	return fp.Check(digest)
+}
+
 func (t *GnarkGroth16) setup(vpKey *VPKey) error {
 	// read proving and verifying keys
 	t.pk = vpKey.PK
 	if t.pk == nil {
-		fmt.Println("pk is nil, generating pk from hex")
+		telemetry.Log().Debug("reading proving key from hex", "circuit", t.name)
 		t.pk = groth16.NewProvingKey(ecc.BN254)
 		{
 			pkBuf := bytes.NewBuffer(common.FromHex(vpKey.ProvingKey))
 			if _, err := t.pk.ReadFrom(pkBuf); err != nil {
-				log.Println(err, "reading proving key failed")
+				telemetry.Log().Error("reading proving key failed", "circuit", t.name, "err", err)
 				return err
 			}
 		}
 	}
 	t.vk = vpKey.VK
 	if t.vk == nil {
-		fmt.Println("vk is nil, generating vk from hex")
+		telemetry.Log().Debug("reading verifying key from hex", "circuit", t.name)
 		t.vk = groth16.NewVerifyingKey(ecc.BN254)
 		{
 			vkBuf := bytes.NewBuffer(common.FromHex(vpKey.VerifyingKey))
 			if _, err := t.vk.ReadFrom(vkBuf); err != nil {
-				log.Println(err, "reading verifying key failed")
+				telemetry.Log().Error("reading verifying key failed", "circuit", t.name, "err", err)
 				return err
 			}
 		}
@@ -63,31 +121,58 @@ func (t *GnarkGroth16) setup(vpKey *VPKey) error {
 	return nil
 }
 
-func (t *GnarkGroth16) GenerateProof(assignment frontend.Circuit) (*Proof, groth16.Proof, error) {
+// GenerateProof proves assignment. When ctx is done it returns ctx.Err() at once, also while groth16.Prove runs: gnark
+// v0.7.1 can't interrupt Prove, so an abandoned proof keeps its CPU and memory in a background goroutine until it
+// completes, and no phase starts after it. Progress (WithProgress) is reported per phase, not inside Prove.
+func (t *GnarkGroth16) GenerateProof(ctx context.Context, assignment frontend.Circuit, opts ...ProveOption) (*Proof, groth16.Proof, error) {
+	start := time.Now()
+	cfg := proveConfig{circuit: t.name}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.verifierDigest != nil {
+		if err := t.CheckVerifierFingerprint(*cfg.verifierDigest); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// witness creation
-	witness, err := frontend.NewWitness(assignment, ecc.BN254)
-	if err != nil {
+	var witness, publicWitness *witness.Witness
+	if err := cfg.run(ctx, PhaseWitness, func() error {
+		// a proof of non canonical public inputs would only verify off-chain
+		if err := validateAssignment(assignment); err != nil {
+			return err
+		}
+		var err error
+		if witness, err = frontend.NewWitness(assignment, ecc.BN254); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidWitness, err)
+		}
+		if publicWitness, err = witness.Public(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidWitness, err)
+		}
+		return nil
+	}); err != nil {
 		return nil, nil, err
 	}
 
-	fmt.Println("GP prove:", time.Now().Format(time.RFC3339))
 	// prove
-	proof, err := groth16.Prove(t.r1cs, t.pk, witness)
+	proof, err := t.prove(ctx, witness, &cfg)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// ensure gnark (Go) code verifies it
-	publicWitness, err := witness.Public()
-	if err != nil {
-		return nil, nil, err
+	if !cfg.skipSelfVerify {
+		if err := cfg.run(ctx, PhaseVerify, func() error {
+			if err := groth16.Verify(proof, t.vk, publicWitness); err != nil {
+				return fmt.Errorf("%w: self-verification failed: %v", ErrProofInvalid, err)
+			}
+			return nil
+		}); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	fmt.Println("GP Verify:", time.Now().Format(time.RFC3339))
-	if err := groth16.Verify(proof, t.vk, publicWitness); err != nil {
-		return nil, nil, err
-	}
-	fmt.Println("GP Verify done:", time.Now().Format(time.RFC3339))
 	// get proof bytes
 	var proofBuffer bytes.Buffer
 	if _, err := proof.WriteRawTo(&proofBuffer); err != nil {
@@ -96,27 +181,45 @@ func (t *GnarkGroth16) GenerateProof(assignment frontend.Circuit) (*Proof, groth
 	proofBytes := proofBuffer.Bytes()
 	proofStruct := ParserProof(proofBytes)
 
+	telemetry.Log().Debug("proof generated", "circuit", t.name, "took", time.Since(start))
+	telemetry.Stats().ProofGenerated(t.name, time.Since(start))
 	return proofStruct, proof, nil
 }
 
+// VerifyProof verifies proof with the public inputs of assignment. Malformed inputs give ErrInvalidPublicInput, a proof
+// that doesn't verify gives ErrProofInvalid.
 func (t *GnarkGroth16) VerifyProof(assignment frontend.Circuit, proof groth16.Proof) (bool, error) {
+	if proof == nil {
+		t.verificationFailed("proof_encoding", nil)
+		return false, fmt.Errorf("%w: missing proof", ErrProofInvalid)
+	}
+	if err := validateAssignment(assignment); err != nil {
+		t.verificationFailed("public_input", err)
+		return false, err
+	}
+
 	// witness creation
 	witness, err := frontend.NewWitness(assignment, ecc.BN254)
 	if err != nil {
-		fmt.Println("NewWitness failed", err)
-		return false, err
+		t.verificationFailed("witness", err)
+		return false, fmt.Errorf("%w: %v", ErrInvalidPublicInput, err)
 	}
 
 	publicWitness, err := witness.Public()
 	if err != nil {
-		fmt.Println("PublicWitness failed", err)
-		return false, err
+		t.verificationFailed("public_witness", err)
+		return false, fmt.Errorf("%w: %v", ErrInvalidPublicInput, err)
 	}
 
 	if err := groth16.Verify(proof, t.vk, publicWitness); err != nil {
-		fmt.Println("Verify failed", err)
-		return false, err
+		t.verificationFailed("pairing", err)
+		return false, fmt.Errorf("%w: %v", ErrProofInvalid, err)
 	} else {
 		return true, nil
 	}
 }
+
+func (t *GnarkGroth16) verificationFailed(reason string, err error) {
+	telemetry.Log().Debug("verification failed", "circuit", t.name, "reason", reason, "err", err)
+	telemetry.Stats().VerificationFailed(t.name, reason)
+}
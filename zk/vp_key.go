@@ -0,0 +1,36 @@
+package zk
+
+import (
+	"sync"
+)
+
+// KeysDir is the bundle directory embedded in the package, written by `make build` and `make build-bid`
+const KeysDir = "zk/keys"
+
+var defaultBundle struct {
+	once   sync.Once
+	bundle *Bundle
+	err    error
+}
+
+// DefaultBundle returns the bundle embedded in the package
+func DefaultBundle() (*Bundle, error) {
+	defaultBundle.once.Do(func() {
+		fsys, err := embeddedBundle()
+		if err != nil {
+			defaultBundle.err = err
+			return
+		}
+		defaultBundle.bundle, defaultBundle.err = LoadBundle(fsys)
+	})
+	return defaultBundle.bundle, defaultBundle.err
+}
+
+// GetVPKey returns the keys of a circuit from the embedded bundle
+func GetVPKey(name string) (*VPKey, error) {
+	bundle, err := DefaultBundle()
+	if err != nil {
+		return nil, err
+	}
+	return bundle.VPKey(name)
+}
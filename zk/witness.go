@@ -0,0 +1,211 @@
+package zk
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/schema"
+	"math/big"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WitnessSchema lists the variables of a circuit in declaration order, the paths are the Go field paths of the
+// circuit struct, e.g. UserData.UserID or UserMerklePath[3]
+type WitnessSchema struct {
+	Circuit string         `json:"circuit"`
+	Fields  []WitnessField `json:"fields"`
+}
+
+// WitnessField is a variable of a circuit, visibility is "public" or "secret"
+type WitnessField struct {
+	Path       string `json:"path"`
+	Visibility string `json:"visibility"`
+}
+
+// WitnessFile is the JSON witness of a registered circuit: the values are decimal or 0x prefixed hexadecimal
+// strings keyed by the paths of its WitnessSchema
+type WitnessFile struct {
+	Circuit string            `json:"circuit"`
+	Values  map[string]string `json:"values"`
+}
+
+// NewWitnessSchema returns the schema of a registered circuit
+func NewWitnessSchema(name string) (*WitnessSchema, error) {
+	circuit, err := NewCircuit(name)
+	if err != nil {
+		return nil, err
+	}
+	leaves, err := witnessLeaves(circuit)
+	if err != nil {
+		return nil, err
+	}
+	s := &WitnessSchema{Circuit: name, Fields: make([]WitnessField, len(leaves))}
+	for i, leaf := range leaves {
+		s.Fields[i] = WitnessField{Path: leaf.path, Visibility: leaf.visibility.String()}
+	}
+	return s, nil
+}
+
+// Template returns a witness file with an empty value for each variable, to fill in
+func (s *WitnessSchema) Template() *WitnessFile {
+	w := &WitnessFile{Circuit: s.Circuit, Values: make(map[string]string, len(s.Fields))}
+	for _, f := range s.Fields {
+		w.Values[f.Path] = ""
+	}
+	return w
+}
+
+// NewWitnessFile returns the witness file of an assignment of the registered circuit name
+func NewWitnessFile(name string, assignment frontend.Circuit) (*WitnessFile, error) {
+	leaves, err := witnessLeaves(assignment)
+	if err != nil {
+		return nil, err
+	}
+	w := &WitnessFile{Circuit: name, Values: make(map[string]string, len(leaves))}
+	for _, leaf := range leaves {
+		value, ok := assignmentValue(leaf.v.Interface())
+		if !ok || value == nil {
+			return nil, fmt.Errorf("%w: %s is not assigned an integer", ErrInvalidWitness, leaf.path)
+		}
+		w.Values[leaf.path] = value.String()
+	}
+	return w, nil
+}
+
+// ReadWitnessFile reads a JSON witness file
+func ReadWitnessFile(fileName string) (*WitnessFile, error) {
+	raw, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var w WitnessFile
+	if err := json.Unmarshal(raw, &w); err != nil {
+		return nil, fmt.Errorf("%w: %s %v", ErrInvalidWitness, fileName, err)
+	}
+	return &w, nil
+}
+
+// WriteFile writes the witness as indented JSON
+func (w *WitnessFile) WriteFile(fileName string) error {
+	raw, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, append(raw, '\n'), 0o600)
+}
+
+// Assignment builds the gnark assignment of the witness, every variable of the circuit must have a value
+func (w *WitnessFile) Assignment() (frontend.Circuit, error) {
+	assignment, err := NewCircuit(w.Circuit)
+	if err != nil {
+		return nil, err
+	}
+	leaves, err := witnessLeaves(assignment)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(leaves))
+	for _, leaf := range leaves {
+		known[leaf.path] = true
+		s, ok := w.Values[leaf.path]
+		if !ok || s == "" {
+			return nil, fmt.Errorf("%w: %s is missing", ErrInvalidWitness, leaf.path)
+		}
+		value, err := parseWitnessValue(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %v", ErrInvalidWitness, leaf.path, err)
+		}
+		leaf.v.Set(reflect.ValueOf(value))
+	}
+	for path := range w.Values {
+		if !known[path] {
+			return nil, fmt.Errorf("%w: %s is not a variable of %s", ErrInvalidWitness, path, w.Circuit)
+		}
+	}
+	return assignment, nil
+}
+
+func parseWitnessValue(s string) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	value, ok := new(big.Int), false
+	if hex := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"); hex != s {
+		value, ok = value.SetString(hex, 16)
+	} else {
+		value, ok = value.SetString(s, 10)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%q is not a decimal or 0x hexadecimal integer", s)
+	}
+	if err := checkCanonical(value, fr.Modulus()); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// witnessLeaf is a variable of a circuit, v is settable
+type witnessLeaf struct {
+	path       string
+	visibility schema.Visibility
+	v          reflect.Value
+}
+
+// witnessLeaves walks the variables of a circuit with schema.Parse, in the order of the witness of gnark, and names
+// them with their Go paths
+func witnessLeaves(circuit frontend.Circuit) ([]witnessLeaf, error) {
+	v := reflect.ValueOf(circuit)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: the circuit must be a pointer to a struct", ErrInvalidWitness)
+	}
+	paths := make(map[uintptr]string)
+	goPaths(paths, v.Elem(), "")
+
+	var leaves []witnessLeaf
+	if _, err := schema.Parse(circuit, tVariable, func(visibility schema.Visibility, name string, v reflect.Value) error {
+		path, ok := paths[v.UnsafeAddr()]
+		if !ok {
+			return fmt.Errorf("%s has no Go path", name)
+		}
+		leaves = append(leaves, witnessLeaf{path: path, visibility: visibility, v: v})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWitness, err)
+	}
+	return leaves, nil
+}
+
+// goPaths records the Go path of each variable of v by address, the leaf handler of schema.Parse gets the variables
+// with their gnark names only
+func goPaths(paths map[uintptr]string, v reflect.Value, path string) {
+	if v.Type() == tVariable {
+		if _, ok := paths[v.UnsafeAddr()]; !ok {
+			paths[v.UnsafeAddr()] = path
+		}
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			goPaths(paths, v.Elem(), path)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() && !f.Anonymous {
+				continue
+			}
+			fieldPath := f.Name
+			if path != "" {
+				fieldPath = path + "." + f.Name
+			}
+			goPaths(paths, v.Field(i), fieldPath)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			goPaths(paths, v.Index(i), path+"["+strconv.Itoa(i)+"]")
+		}
+	}
+}
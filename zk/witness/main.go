@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"gnark-bid/zk"
+	"log"
+	"os"
+	"strings"
+)
+
+const usage = `usage: witness <command> [flags]
+
+JSON witnesses of the registered circuits, keyed by the field paths of the circuit (e.g. UserData.UserID).
+
+commands:
+  schema  print the variables of a circuit, or write an empty witness file to fill in
+  check   load a witness file and check that it solves its circuit
+
+circuits: %s
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintf(os.Stderr, usage, strings.Join(zk.CircuitNames(), ", "))
+		os.Exit(2)
+	}
+	cmd, args := os.Args[1], os.Args[2:]
+	switch cmd {
+	case "schema":
+		schema(args)
+	case "check":
+		check(args)
+	default:
+		fmt.Fprintf(os.Stderr, usage, strings.Join(zk.CircuitNames(), ", "))
+		os.Exit(2)
+	}
+}
+
+func schema(args []string) {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	circuitName := fs.String("circuit", "BiddingCircuit", "registered circuit")
+	template := fs.String("template", "", "write an empty witness file instead")
+	_ = fs.Parse(args)
+
+	s, err := zk.NewWitnessSchema(*circuitName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *template != "" {
+		if err := s.Template().WriteFile(*template); err != nil {
+			log.Fatal("write template error:", err)
+		}
+		fmt.Println("template written:", *template)
+		return
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func check(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	in := fs.String("in", "witness.json", "witness file")
+	_ = fs.Parse(args)
+
+	w, err := zk.ReadWitnessFile(*in)
+	if err != nil {
+		log.Fatal("read witness error:", err)
+	}
+	assignment, err := w.Assignment()
+	if err != nil {
+		log.Fatal(err)
+	}
+	circuit, err := zk.NewCircuit(w.Circuit)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r1csCompiled, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, circuit)
+	if err != nil {
+		log.Fatal("compile error:", err)
+	}
+	witness, err := frontend.NewWitness(assignment, ecc.BN254)
+	if err != nil {
+		log.Fatal("witness error:", err)
+	}
+	if err := r1csCompiled.IsSolved(witness); err != nil {
+		log.Fatal("the witness doesn't solve ", w.Circuit, ": ", err)
+	}
+	fmt.Println("witness ok:", w.Circuit)
+}
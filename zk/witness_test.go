@@ -0,0 +1,112 @@
+package zk_test
+
+import (
+	"context"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/schema"
+	"github.com/consensys/gnark/test"
+	"gnark-bid/zk"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWitnessSchema(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	s, err := zk.NewWitnessSchema("BiddingCircuit")
+	assert.NoError(err)
+	assert.Equal(1+(zk.MerkleTreeDepth+1)+zk.MerkleTreeDepth+2+3+1, len(s.Fields))
+	visibility := make(map[string]string)
+	for _, f := range s.Fields {
+		visibility[f.Path] = f.Visibility
+	}
+	assert.Equal("public", visibility["UserMerkleRoot"])
+	assert.Equal("secret", visibility["UserMerklePath[3]"])
+	assert.Equal("secret", visibility["UserData.UserID"])
+	assert.Equal("public", visibility["Identity.Trapdoor"])
+	assert.Equal("public", visibility["BidValue"])
+
+	// the variables of gnark, in the same order
+	circuit, err := zk.NewCircuit("BiddingCircuit")
+	assert.NoError(err)
+	var names []string
+	_, err = schema.Parse(circuit, reflect.TypeOf((*frontend.Variable)(nil)).Elem(), func(_ schema.Visibility, name string, _ reflect.Value) error {
+		names = append(names, name)
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal(len(names), len(s.Fields))
+	for i, f := range s.Fields {
+		assert.Equal(strings.NewReplacer(".", "_", "[", "_", "]", "").Replace(f.Path), names[i])
+	}
+
+	_, err = s.Template().Assignment()
+	assert.ErrorIs(err, zk.ErrInvalidWitness)
+	_, err = zk.NewWitnessSchema("NoCircuit")
+	assert.Error(err)
+}
+
+func TestWitnessFile(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	w, err := zk.ReadWitnessFile("testdata/bidding_witness.json")
+	assert.NoError(err)
+	assignment, err := w.Assignment()
+	assert.NoError(err)
+
+	bundle, err := zk.DefaultBundle()
+	assert.NoError(err)
+	circuit, err := zk.NewCircuit(w.Circuit)
+	assert.NoError(err)
+	g16, err := bundle.GnarkGroth16(w.Circuit, circuit)
+	assert.NoError(err)
+	_, proof, err := g16.GenerateProof(context.Background(), assignment)
+	assert.NoError(err)
+	ok, err := g16.VerifyProof(assignment, proof)
+	assert.NoError(err)
+	assert.True(ok)
+
+	// round trip
+	again, err := zk.NewWitnessFile(w.Circuit, assignment)
+	assert.NoError(err)
+	assert.Equal(w, again)
+	fileName := filepath.Join(t.TempDir(), "witness.json")
+	assert.NoError(again.WriteFile(fileName))
+	read, err := zk.ReadWitnessFile(fileName)
+	assert.NoError(err)
+	assert.Equal(w, read)
+
+	// hexadecimal values
+	hex := &zk.WitnessFile{Circuit: w.Circuit, Values: make(map[string]string)}
+	for path, value := range w.Values {
+		hex.Values[path] = value
+	}
+	hex.Values["BidValue"] = "0x64"
+	hexAssignment, err := hex.Assignment()
+	assert.NoError(err)
+	ok, err = g16.VerifyProof(hexAssignment, proof)
+	assert.NoError(err)
+	assert.True(ok)
+
+	for _, c := range []struct {
+		path, value string
+	}{
+		{"BidValue", ""},
+		{"BidValue", "one hundred"},
+		{"BidValue", "-100"},
+		{"UserData.UserID", fr.Modulus().String()},
+		{"UserData.UserName", "1"},
+		{"UserMerklePath[6]", "1"},
+	} {
+		invalid := &zk.WitnessFile{Circuit: w.Circuit, Values: make(map[string]string)}
+		for path, value := range w.Values {
+			invalid.Values[path] = value
+		}
+		invalid.Values[c.path] = c.value
+		_, err = invalid.Assignment()
+		assert.ErrorIs(err, zk.ErrInvalidWitness, "%s: %q", c.path, c.value)
+	}
+}
@@ -2,6 +2,7 @@ package zk_test
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	gnarkMerkleTree "github.com/consensys/gnark-crypto/accumulator/merkletree"
 	"github.com/consensys/gnark-crypto/ecc"
@@ -12,8 +13,11 @@ import (
 	"github.com/consensys/gnark/test"
 	"github.com/iden3/go-iden3-crypto/poseidon"
 	"github.com/thoas/go-funk"
+	"gnark-bid/circuits"
 	merkle "gnark-bid/merkle"
+	"gnark-bid/zk"
 	"gnark-bid/zk/circuits"
+	"gnark-bid/zk/zktest"
 	"math/big"
 	"testing"
 )
@@ -109,3 +113,40 @@ func TestMerkleTree(t *testing.T) {
 		fmt.Println("Verify success")
 	}
 }
+
+func TestBiddingWithHash(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// the embedded keys are MiMC keys
+	_, err := zk.NewBidding(nil, zk.WithHash(circuits.HashPoseidon))
+	assert.True(errors.Is(err, zk.ErrKeyMismatch), "%v", err)
+	_, err = zk.NewBidding(nil, zk.WithHash("md5"))
+	assert.Error(err)
+
+	circuit := zk_circuit.BiddingCircuit{Hash: circuits.HashPoseidon}
+	circuit.UserMerklePath = make([]frontend.Variable, zk.MerkleTreeDepth+1)
+	circuit.UserMerkleHelper = make([]frontend.Variable, zk.MerkleTreeDepth)
+	r1csCompiled, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit)
+	assert.NoError(err, "compilation failed")
+	pk, vk, err := zktest.UnsafeSeededSetup(r1csCompiled, []byte(t.Name()))
+	assert.NoError(err)
+	vpKey, err := zk.CreateVPKey(pk, vk)
+	assert.NoError(err)
+	g16, err := zk.NewGnarkGroth16WithCS(vpKey, r1csCompiled, &circuit)
+	assert.NoError(err)
+
+	bidding, err := zk.NewBiddingWithProver(g16, zk.WithHash(circuits.HashPoseidon))
+	assert.NoError(err)
+	assert.NoError(bidding.InitSession(1111, "username_3", big.NewInt(1234)))
+	proof, inputs, err := bidding.GetProofInputs(big.NewInt(100))
+	assert.NoError(err)
+	ok, err := bidding.VerifyProofInputs(proof, inputs)
+	assert.NoError(err)
+	assert.True(ok)
+
+	// a MiMC session doesn't use the Poseidon keys
+	_, err = zk.NewBiddingWithProver(g16)
+	assert.True(errors.Is(err, zk.ErrKeyMismatch), "%v", err)
+	_, err = zk.NewBiddingWithProver(g16, zk.WithHash(circuits.HashMiMC))
+	assert.True(errors.Is(err, zk.ErrKeyMismatch), "%v", err)
+}
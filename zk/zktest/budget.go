@@ -0,0 +1,27 @@
+// Package zktest contains helpers for the tests of the circuits
+package zktest
+
+import (
+	"gnark-bid/zk"
+	"testing"
+)
+
+// CheckBudget compiles the circuits of a budget file (see zk.Budget) and fails when they exceed it. After an intended
+// change, rewrite the file with `go run ./zk/stats budget`.
+func CheckBudget(tb testing.TB, budgetFile string) {
+	tb.Helper()
+	budget, err := zk.ReadBudget(budgetFile)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	report, err := zk.CircuitReport(budget.Circuits()...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for _, s := range report {
+		tb.Logf("%s: %d constraints", s.Circuit, s.NbConstraints)
+	}
+	if err := budget.Check(report); err != nil {
+		tb.Fatal(err)
+	}
+}
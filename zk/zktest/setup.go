@@ -0,0 +1,75 @@
+package zktest
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"gnark-bid/zk"
+	"io"
+	"math/bits"
+)
+
+const seededSetupDST = "GNARK-BID-UNSAFE-SEEDED-SETUP-V3"
+
+// UnsafeSeededSetup derives the keys of r1cs from seed, so that the same seed always gives the same keys: a one
+// participant phase 1 (zk.PowersOfTau) and phase 2 (zk.Ceremony) whose secrets are read from a stream derived from
+// the seed instead of crypto/rand.
+//
+// UNSAFE: anyone who knows the seed can forge proofs. It is meant for tests and for CI to reproduce and diff keys,
+// verifiers and bindings; production keys come from a Ceremony.
+func UnsafeSeededSetup(r1cs frontend.CompiledConstraintSystem, seed []byte) (groth16.ProvingKey, groth16.VerifyingKey, error) {
+	random := &seededReader{seed: seed}
+
+	power := bits.TrailingZeros64(fft.NewDomain(uint64(r1cs.GetNbConstraints())).Cardinality)
+	if power < 1 {
+		power = 1
+	}
+	phase1, err := zk.NewPowersOfTau(power)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := phase1.Contribute("unsafe seed", random); err != nil {
+		return nil, nil, err
+	}
+	ceremony, err := zk.NewCeremony("unsafe seeded setup", r1cs, phase1)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ceremony.Contribute("unsafe seed", random); err != nil {
+		return nil, nil, err
+	}
+	key, err := ceremony.Finalize(r1cs, phase1)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key.PK, key.VK, nil
+}
+
+// seededReader is the sha256 of the seed in counter mode
+type seededReader struct {
+	seed    []byte
+	counter uint64
+	block   []byte
+}
+
+var _ io.Reader = (*seededReader)(nil)
+
+func (r *seededReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.block) == 0 {
+			h := sha256.New()
+			h.Write([]byte(seededSetupDST))
+			_ = binary.Write(h, binary.BigEndian, r.counter)
+			h.Write(r.seed)
+			r.block = h.Sum(nil)
+			r.counter++
+		}
+		c := copy(p[n:], r.block)
+		r.block = r.block[c:]
+		n += c
+	}
+	return n, nil
+}
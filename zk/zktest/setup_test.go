@@ -0,0 +1,92 @@
+package zktest_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"gnark-bid/zk"
+	"gnark-bid/zk/circuits"
+	"gnark-bid/zk/zktest"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnsafeSeededSetup(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var circuit zk_circuit.PrivateValueCircuit
+	r1csCompiled, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit)
+	assert.NoError(err, "compilation failed")
+
+	raw := func(seed string) ([]byte, []byte) {
+		pk, vk, err := zktest.UnsafeSeededSetup(r1csCompiled, []byte(seed))
+		assert.NoError(err, "setup failed")
+		var bufPk, bufVk bytes.Buffer
+		_, err = pk.WriteRawTo(&bufPk)
+		assert.NoError(err)
+		_, err = vk.WriteRawTo(&bufVk)
+		assert.NoError(err)
+		return bufPk.Bytes(), bufVk.Bytes()
+	}
+	pk1, vk1 := raw("ci")
+	pk2, vk2 := raw("ci")
+	assert.Equal(pk1, pk2, "same seed, same proving key")
+	assert.Equal(vk1, vk2, "same seed, same verifying key")
+	pk3, vk3 := raw("another seed")
+	assert.NotEqual(pk1, pk3)
+	assert.NotEqual(vk1, vk3)
+
+	// the seeded keys are regular keys
+	pk, vk, err := zktest.UnsafeSeededSetup(r1csCompiled, []byte("ci"))
+	assert.NoError(err)
+	assert.NoError(zk.CheckConstraintSystem(r1csCompiled, pk, vk, &circuit))
+	vpKey, err := zk.CreateVPKey(pk, vk)
+	assert.NoError(err)
+	g16, err := zk.NewGnarkGroth16WithCS(vpKey, r1csCompiled, &circuit)
+	assert.NoError(err)
+
+	value := big.NewInt(42)
+	assignment := &zk_circuit.PrivateValueCircuit{PrivateValue: value, Hash: zk_circuit.HashMIMC(value.Bytes())}
+	_, proof, err := g16.GenerateProof(context.Background(), assignment)
+	assert.NoError(err)
+	ok, err := g16.VerifyProof(assignment, proof)
+	assert.NoError(err)
+	assert.True(ok)
+
+	// the keys of groth16.Setup don't verify proofs of the seeded keys
+	_, otherVK, err := groth16.Setup(r1csCompiled)
+	assert.NoError(err)
+	publicWitness, err := frontend.NewWitness(assignment, ecc.BN254, frontend.PublicOnly())
+	assert.NoError(err)
+	assert.Error(groth16.Verify(proof, otherVK, publicWitness))
+}
+
+func TestSeededVerifierIsReproducible(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	generate := func() string {
+		dir := filepath.Join(t.TempDir(), "solidity")
+		assert.NoError(os.Mkdir(dir, 0o755))
+		var circuit zk_circuit.PrivateValueCircuit
+		_, _, err := zk.GenerateGroth16R1csCompiler(&circuit, "PrivateValueCircuit", false,
+			zk.WithSetup(func(r1cs frontend.CompiledConstraintSystem) (groth16.ProvingKey, groth16.VerifyingKey, error) {
+				return zktest.UnsafeSeededSetup(r1cs, []byte("ci"))
+			}), zk.WithSolidityDir(dir))
+		assert.NoError(err)
+		return dir
+	}
+	dir1, dir2 := generate(), generate()
+	for _, name := range []string{"Contract_PrivateValueCircuit.sol", "solidity_Fingerprint_PrivateValueCircuit.go"} {
+		b1, err := os.ReadFile(filepath.Join(dir1, name))
+		assert.NoError(err)
+		b2, err := os.ReadFile(filepath.Join(dir2, name))
+		assert.NoError(err)
+		assert.Equal(b1, b2, name)
+	}
+}